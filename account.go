@@ -1,6 +1,7 @@
 package appolloxapi
 
 import (
+	"context"
 	"net/http"
 )
 
@@ -9,6 +10,7 @@ type AccountResponse struct {
 	CanTrade                    bool                 `json:"canTrade"`
 	CanDeposit                  bool                 `json:"canDeposit"`
 	CanWithdraw                 bool                 `json:"canWithdraw"`
+	MultiAssetsMargin           bool                 `json:"multiAssetsMargin"`
 	UpdateTime                  int64                `json:"updateTime"`
 	TotalInitialMargin          string               `json:"totalInitialMargin"`
 	TotalMaintMargin            string               `json:"totalMaintMargin"`
@@ -56,7 +58,11 @@ type PositionsInAccount struct {
 }
 
 func (b *Client) Account() (*AccountResponse, error) {
-	res, err := b.do(http.MethodGet, "fapi/v2/account", nil, true, false)
+	return b.AccountWithContext(context.Background())
+}
+
+func (b *Client) AccountWithContext(ctx context.Context) (*AccountResponse, error) {
+	res, err := b.do(ctx, http.MethodGet, "fapi/v2/account", nil, true, false)
 	if err != nil {
 		return nil, err
 	}
@@ -70,7 +76,7 @@ func (b *Client) Account() (*AccountResponse, error) {
 
 type BalanceResponse struct {
 	AccountAlias       string `json:"accountAlias"`
-	Asset              string `json:"asset`
+	Asset              string `json:"asset"`
 	Balace             string `json:"balance"`
 	CrossWalletBalance string `json:"crossWalletBalance"`
 	AvailableBalance   string `json:"availableBalance"`
@@ -78,7 +84,11 @@ type BalanceResponse struct {
 }
 
 func (b *Client) Balance() ([]*BalanceResponse, error) {
-	res, err := b.do(http.MethodGet, "fapi/v2/balance", nil, true, false)
+	return b.BalanceWithContext(context.Background())
+}
+
+func (b *Client) BalanceWithContext(ctx context.Context) ([]*BalanceResponse, error) {
+	res, err := b.do(ctx, http.MethodGet, "fapi/v2/balance", nil, true, false)
 	if err != nil {
 		return nil, err
 	}
@@ -91,6 +101,10 @@ func (b *Client) Balance() ([]*BalanceResponse, error) {
 }
 
 func (b *Client) GetIncomeHistory(method, symbol string, limit int, start, end int64) ([]*IncomeResponse, error) {
+	return b.GetIncomeHistoryWithContext(context.Background(), method, symbol, limit, start, end)
+}
+
+func (b *Client) GetIncomeHistoryWithContext(ctx context.Context, method, symbol string, limit int, start, end int64) ([]*IncomeResponse, error) {
 	opts := IncomeHisOpts{
 		Symbol:     symbol,
 		Limit:      limit,
@@ -103,7 +117,7 @@ func (b *Client) GetIncomeHistory(method, symbol string, limit int, start, end i
 	if opts.Limit == 0 || opts.Limit > 1000 {
 		opts.Limit = 1000
 	}
-	res, err := b.do(http.MethodGet, "fapi/v1/income", opts, true, false)
+	res, err := b.do(ctx, http.MethodGet, "fapi/v1/income", opts, true, false)
 	if err != nil {
 		return nil, err
 	}
@@ -140,15 +154,20 @@ type CommissionRateResponse struct {
 	Takercommissionrate string `json:"takerCommissionRate"`
 }
 
+// empty symbol is left out
 type onlySymbolOpts struct {
-	Symbol string `json:"symbol"`
+	Symbol string `url:"symbol,omitempty"`
 }
 
 func (b *Client) CommissionRate(symbol string) (*CommissionRateResponse, error) {
+	return b.CommissionRateWithContext(context.Background(), symbol)
+}
+
+func (b *Client) CommissionRateWithContext(ctx context.Context, symbol string) (*CommissionRateResponse, error) {
 	input := onlySymbolOpts{
 		Symbol: symbol,
 	}
-	res, err := b.do(http.MethodGet, "fapi/v1/commissionRate", input, true, false)
+	res, err := b.do(ctx, http.MethodGet, "fapi/v1/commissionRate", input, true, false)
 	if err != nil {
 		return nil, err
 	}
@@ -176,7 +195,11 @@ type PositionResponse struct {
 }
 
 func (b *Client) Positions() ([]*PositionResponse, error) {
-	res, err := b.do(http.MethodGet, "fapi/v2/positionRisk", nil, true, false)
+	return b.PositionsWithContext(context.Background())
+}
+
+func (b *Client) PositionsWithContext(ctx context.Context) ([]*PositionResponse, error) {
+	res, err := b.do(ctx, http.MethodGet, "fapi/v2/positionRisk", nil, true, false)
 	if err != nil {
 		return nil, err
 	}
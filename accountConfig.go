@@ -0,0 +1,94 @@
+package appolloxapi
+
+import (
+	"strconv"
+	"time"
+)
+
+// one ACCOUNT_CONFIG_UPDATE after the local account took it in, one of Leverage or MultiAssets is set
+type AccountConfigChange struct {
+	Time        time.Time
+	Leverage    *LeverageConfig
+	MultiAssets *MultiAssetsConfig
+	// leverage of the symbol before, 0 when it was not in the account
+	PrevLeverage int
+}
+
+// leverage of the symbol, kept up by the stream
+func (u *UserDataBranch) Leverage(symbol string) (int, bool) {
+	u.account.RLock()
+	defer u.account.RUnlock()
+	if u.account.Data == nil {
+		return 0, false
+	}
+	for _, position := range u.account.Data.Positions {
+		if position.Symbol == symbol {
+			leverage, err := strconv.Atoi(position.Leverage)
+			return leverage, err == nil
+		}
+	}
+	return 0, false
+}
+
+func (u *UserDataBranch) MultiAssetsMargin() bool {
+	u.account.RLock()
+	defer u.account.RUnlock()
+	return u.account.Data != nil && u.account.Data.MultiAssetsMargin
+}
+
+// leverage and multi-assets changes, made here or anywhere else
+func (u *UserDataBranch) SubscribeConfig(opts SubscribeOpts) (<-chan AccountConfigChange, *Subscription) {
+	ch := make(chan AccountConfigChange)
+	sub := u.subs.add(TopicConfig, opts, func(item interface{}, stop <-chan struct{}) bool {
+		select {
+		case ch <- item.(AccountConfigChange):
+			return true
+		case <-stop:
+			return false
+		}
+	}, func() { close(ch) })
+	return ch, sub
+}
+
+func (u *UserDataBranch) OnConfig(opts SubscribeOpts, handler func(AccountConfigChange)) *Subscription {
+	return u.subs.add(TopicConfig, opts, func(item interface{}, stop <-chan struct{}) bool {
+		handler(item.(AccountConfigChange))
+		return true
+	}, nil)
+}
+
+// internal funcs ------------------------------------------------
+
+func (u *UserDataBranch) handleAccountConfigUpdate(event *AccountConfigUpdateEvent) {
+	change := AccountConfigChange{
+		Time:        event.EventTime(),
+		Leverage:    event.Leverage,
+		MultiAssets: event.MultiAssets,
+	}
+	u.account.Lock()
+	if u.account.Data != nil {
+		if event.Leverage != nil {
+			change.PrevLeverage = u.updateLeverage(event.Leverage.Symbol, event.Leverage.Leverage)
+		}
+		if event.MultiAssets != nil {
+			u.account.Data.MultiAssetsMargin = event.MultiAssets.MultiAssetsMargin
+		}
+	}
+	u.account.Unlock()
+	u.subs.publish(TopicConfig, change)
+}
+
+// every side of the symbol shares the leverage, called with the lock held
+func (u *UserDataBranch) updateLeverage(symbol string, leverage int) int {
+	prev := 0
+	for idx, position := range u.account.Data.Positions {
+		if position.Symbol != symbol {
+			continue
+		}
+		if old, err := strconv.Atoi(position.Leverage); err == nil {
+			prev = old
+		}
+		u.account.Data.Positions[idx].Leverage = strconv.Itoa(leverage)
+	}
+	return prev
+}
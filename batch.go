@@ -0,0 +1,198 @@
+package appolloxapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const (
+	maxBatchPlaceOrders  = 5
+	maxBatchCancelOrders = 10
+	// chunks in flight at once, the rate limiter still paces them
+	maxBatchConcurrency = 4
+)
+
+var ErrBatchTooLarge = errors.New("too many orders for one batch request")
+
+// one item of the input, either Order or Err is set
+type BatchItemResult struct {
+	Order *OrderResponse
+	Err   error
+}
+
+// any number of orders, sent in chunks of 5. the results are in the order of the input.
+// missing client order ids are filled into orders, after an ambiguous failure of a chunk
+// its orders are looked up by them.
+func (b *Client) PlaceOrdersInBatches(orders []PlaceOrderOpts) []BatchItemResult {
+	return b.PlaceOrdersInBatchesWithContext(context.Background(), orders)
+}
+
+func (b *Client) PlaceOrdersInBatchesWithContext(ctx context.Context, orders []PlaceOrderOpts) []BatchItemResult {
+	results := make([]BatchItemResult, len(orders))
+	valid := make([]int, 0, len(orders))
+	for idx := range orders {
+		order := &orders[idx]
+		order.normalize()
+		if err := order.validate(); err != nil {
+			results[idx].Err = err
+			continue
+		}
+		if order.ClientID == "" {
+			order.ClientID = newClientOrderID()
+		}
+		valid = append(valid, idx)
+	}
+	sendInChunks(valid, maxBatchPlaceOrders, func(chunk []int) {
+		batch := make([]PlaceOrderOpts, 0, len(chunk))
+		for _, idx := range chunk {
+			batch = append(batch, orders[idx])
+		}
+		resp, err := b.PlaceBatchOrdersWithContext(ctx, batch)
+		if err != nil && isRetryable(ctx, err) {
+			for _, idx := range chunk {
+				results[idx] = b.lookupBatchOrder(ctx, orders[idx], err)
+			}
+			return
+		}
+		fillBatchResults(results, chunk, resp, err)
+	})
+	return results
+}
+
+// any number of order ids, sent in chunks of 10. the results are in the order of the input.
+func (b *Client) CancelOrdersInBatches(symbol string, oids []int64) []BatchItemResult {
+	return b.CancelOrdersInBatchesWithContext(context.Background(), symbol, oids)
+}
+
+func (b *Client) CancelOrdersInBatchesWithContext(ctx context.Context, symbol string, oids []int64) []BatchItemResult {
+	results := make([]BatchItemResult, len(oids))
+	sendInChunks(batchIndexes(len(oids)), maxBatchCancelOrders, func(chunk []int) {
+		batch := make([]int64, 0, len(chunk))
+		for _, idx := range chunk {
+			batch = append(batch, oids[idx])
+		}
+		resp, err := b.CancelBatchOrdersWithContext(ctx, symbol, batch)
+		fillBatchResults(results, chunk, resp, err)
+	})
+	return results
+}
+
+func (b *Client) CancelOrdersByClientIDInBatches(symbol string, clientIDs []string) []BatchItemResult {
+	return b.CancelOrdersByClientIDInBatchesWithContext(context.Background(), symbol, clientIDs)
+}
+
+func (b *Client) CancelOrdersByClientIDInBatchesWithContext(ctx context.Context, symbol string, clientIDs []string) []BatchItemResult {
+	results := make([]BatchItemResult, len(clientIDs))
+	sendInChunks(batchIndexes(len(clientIDs)), maxBatchCancelOrders, func(chunk []int) {
+		batch := make([]string, 0, len(chunk))
+		for _, idx := range chunk {
+			batch = append(batch, clientIDs[idx])
+		}
+		resp, err := b.CancelBatchOrdersByClientIDWithContext(ctx, symbol, batch)
+		fillBatchResults(results, chunk, resp, err)
+	})
+	return results
+}
+
+// the item as an order, or its error
+func (r *BatchOrderResult) Result() BatchItemResult {
+	if err := r.Err(); err != nil {
+		return BatchItemResult{Err: err}
+	}
+	return BatchItemResult{Order: r.orderResponse()}
+}
+
+// internal funcs ------------------------------------------------
+
+// run send on chunks of the indexes, a few at a time
+func sendInChunks(indexes []int, size int, send func(chunk []int)) {
+	sem := make(chan struct{}, maxBatchConcurrency)
+	var wg sync.WaitGroup
+	for start := 0; start < len(indexes); start += size {
+		end := start + size
+		if end > len(indexes) {
+			end = len(indexes)
+		}
+		chunk := indexes[start:end]
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			send(chunk)
+		}()
+	}
+	wg.Wait()
+}
+
+func batchIndexes(n int) []int {
+	indexes := make([]int, n)
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return indexes
+}
+
+// a failed request fails every item of the chunk
+func fillBatchResults(results []BatchItemResult, chunk []int, resp *BatchOrdersResponse, err error) {
+	if err == nil && len(*resp) != len(chunk) {
+		err = fmt.Errorf("batch response has %d items for %d orders", len(*resp), len(chunk))
+	}
+	for i, idx := range chunk {
+		if err != nil {
+			results[idx].Err = err
+			continue
+		}
+		results[idx] = (*resp)[i].Result()
+	}
+}
+
+func (b *Client) lookupBatchOrder(ctx context.Context, order PlaceOrderOpts, cause error) BatchItemResult {
+	found, err := b.QueryOrderByClientIDWithContext(ctx, order.Symbol, order.ClientID)
+	switch {
+	case err == nil:
+		return BatchItemResult{Order: found.orderResponse()}
+	case errors.Is(err, ErrNoSuchOrder):
+		return BatchItemResult{Err: cause}
+	}
+	return BatchItemResult{Err: fmt.Errorf("order %s in unknown state: %w", order.ClientID, cause)}
+}
+
+func (r *BatchOrderResult) orderResponse() *OrderResponse {
+	return &OrderResponse{
+		ClientOrderID: r.Clientorderid,
+		CumQty:        r.Cumqty,
+		CumQuote:      r.Cumquote,
+		ExecutedQty:   r.Executedqty,
+		OrderID:       r.Orderid,
+		AvgPrice:      r.Avgprice,
+		OrigQty:       r.Origqty,
+		Price:         r.Price,
+		ReduceOnly:    r.Reduceonly,
+		Side:          r.Side,
+		PositionSide:  r.Positionside,
+		Status:        r.Status,
+		StopPrice:     r.Stopprice,
+		Symbol:        r.Symbol,
+		TimeInForce:   r.Timeinforce,
+		Type:          r.Type,
+		OrigType:      r.Origtype,
+		ActivatePrice: r.Activateprice,
+		PriceRate:     r.Pricerate,
+		UpdateTime:    r.Updatetime,
+		WorkingType:   r.Workingtype,
+	}
+}
+
+// keep the single request calls within what the exchange accepts
+func checkBatchSize(n, max int) error {
+	if n == 0 {
+		return errors.New("empty batch")
+	}
+	if n > max {
+		return fmt.Errorf("%w: %d orders, max %d", ErrBatchTooLarge, n, max)
+	}
+	return nil
+}
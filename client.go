@@ -1,6 +1,7 @@
 package appolloxapi
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
@@ -24,26 +25,41 @@ All time and timestamp related fields are in milliseconds.
 All data types adopt definition in JAVA.
 */
 var ENDPOINT = "https://fapi.apollox.finance"
+var WS_ENDPOINT = "wss://fstream.apollox.finance"
 var json = jsoniter.ConfigCompatibleWithStandardLibrary
 
 type Client struct {
 	key, secret string
 	subaccount  string
 	client      *http.Client
+	baseURL     string
+	wsBaseURL   string
+	userAgent   string
 	window      int
+	limiter     *rateLimiter
+	clock       *clockSync
+	retry       RetryPolicy
 }
 
-func New(key, secret, subaccount string) *Client {
+func New(key, secret, subaccount string, opts ...ClientOption) *Client {
 	hc := &http.Client{
 		Timeout: 10 * time.Second,
 	}
-	return &Client{
+	c := &Client{
 		key:        key,
 		secret:     secret,
 		subaccount: subaccount,
 		client:     hc,
+		baseURL:    ENDPOINT,
+		wsBaseURL:  WS_ENDPOINT,
 		window:     5000,
+		limiter:    newRateLimiter(),
+		clock:      &clockSync{},
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // in milliseconds, default is 5000
@@ -51,14 +67,47 @@ func (c *Client) SetRecvWindow(recvWindow int) {
 	c.window = recvWindow
 }
 
-func (c *Client) do(method, path string, data interface{}, sign bool, stream bool) (response []byte, err error) {
+// only GETs are retried here, see submitOrder for new orders
+func (c *Client) do(ctx context.Context, method, path string, data interface{}, sign bool, stream bool) (response []byte, err error) {
+	attempts := 1
+	if method == http.MethodGet {
+		attempts = c.retry.attempts()
+	}
+	for attempt := 0; ; attempt++ {
+		response, err = c.doWithTimeSync(ctx, method, path, data, sign, stream)
+		if err == nil || attempt+1 >= attempts || !isRetryable(ctx, err) {
+			return response, err
+		}
+		if err := c.retry.wait(ctx, attempt); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) doWithTimeSync(ctx context.Context, method, path string, data interface{}, sign bool, stream bool) (response []byte, err error) {
+	response, err = c.doOnce(ctx, method, path, data, sign, stream)
+	if err == nil || !sign || !c.timeSyncEnabled() || !errors.Is(err, ErrTimestampOutsideRecvWindow) {
+		return response, err
+	}
+	// clock drifted, resync once and retry
+	if syncErr := c.SyncServerTime(ctx); syncErr != nil {
+		return nil, err
+	}
+	return c.doOnce(ctx, method, path, data, sign, stream)
+}
+
+func (c *Client) doOnce(ctx context.Context, method, path string, data interface{}, sign bool, stream bool) (response []byte, err error) {
 	values, err := query.Values(data)
 	if err != nil {
 		return nil, err
 	}
+	weight, orders := endpointWeight(method, path, values)
+	if err := c.limiter.acquire(ctx, weight, orders, dataRequests(path)); err != nil {
+		return nil, err
+	}
 	payload := values.Encode()
 	if sign {
-		payload = fmt.Sprintf("%s&timestamp=%v&recvWindow=%d", payload, time.Now().UnixNano()/(1000*1000), c.window)
+		payload = fmt.Sprintf("%s&timestamp=%v&recvWindow=%d", payload, c.timestamp(), c.window)
 		mac := hmac.New(sha256.New, []byte(c.secret))
 		_, err = mac.Write([]byte(payload))
 		if err != nil {
@@ -68,33 +117,47 @@ func (c *Client) do(method, path string, data interface{}, sign bool, stream boo
 	}
 	var req *http.Request
 	if method == http.MethodGet {
-		req, err = http.NewRequest(method, fmt.Sprintf("%s/%s?%s", ENDPOINT, path, payload), nil)
+		req, err = http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s/%s?%s", c.baseURL, path, payload), nil)
+		if err != nil {
+			return nil, err
+		}
 	} else {
-		req, err = http.NewRequest(method, fmt.Sprintf("%s/%s", ENDPOINT, path), strings.NewReader(payload))
+		req, err = http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s/%s", c.baseURL, path), strings.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
 		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 	}
 	if sign || stream {
 		req.Header.Add("X-MBX-APIKEY", c.key)
 	}
 	req.Header.Add("Accept", "application/json")
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
 	resp, err := c.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
+	c.limiter.update(resp.Header, resp.StatusCode)
 	response, err = ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("status %d: %v", resp.StatusCode, string(response))
+		return nil, newAPIError(resp.StatusCode, method, path, response)
 	}
 	return response, err
 }
 
 func (b *Client) Ping() error {
+	return b.PingWithContext(context.Background())
+}
+
+func (b *Client) PingWithContext(ctx context.Context) error {
 	path := "fapi/v1/ping"
-	_, err := b.do(http.MethodGet, path, nil, false, false)
+	_, err := b.do(ctx, http.MethodGet, path, nil, false, false)
 	return err
 }
 
@@ -103,7 +166,11 @@ type ServerTime struct {
 }
 
 func (b *Client) Time() (time.Time, error) {
-	res, err := b.do(http.MethodGet, "fapi/v1/time", nil, false, false)
+	return b.TimeWithContext(context.Background())
+}
+
+func (b *Client) TimeWithContext(ctx context.Context) (time.Time, error) {
+	res, err := b.do(ctx, http.MethodGet, "fapi/v1/time", nil, false, false)
 	if err != nil {
 		return time.Time{}, err
 	}
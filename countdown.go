@@ -0,0 +1,201 @@
+package appolloxapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type CountdownCancelAllOpts struct {
+	Symbol        string `url:"symbol"`
+	CountdownTime int64  `url:"countdownTime"`
+}
+
+type CountdownCancelAllResponse struct {
+	Symbol        string `json:"symbol"`
+	CountdownTime string `json:"countdownTime"`
+}
+
+// every open order of the symbol is canceled when the countdown runs out, 0 disarms it
+func (b *Client) CountdownCancelAll(symbol string, countdown time.Duration) (*CountdownCancelAllResponse, error) {
+	return b.CountdownCancelAllWithContext(context.Background(), symbol, countdown)
+}
+
+func (b *Client) CountdownCancelAllWithContext(ctx context.Context, symbol string, countdown time.Duration) (*CountdownCancelAllResponse, error) {
+	opts := CountdownCancelAllOpts{
+		Symbol:        strings.ToUpper(symbol),
+		CountdownTime: int64(countdown / time.Millisecond),
+	}
+	res, err := b.do(ctx, http.MethodPost, "fapi/v1/countdownCancelAll", opts, true, false)
+	if err != nil {
+		return nil, err
+	}
+	resp := &CountdownCancelAllResponse{}
+	err = json.Unmarshal(res, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// a re-arm that failed, the orders of Symbol get canceled once the countdown runs out
+type HeartbeatError struct {
+	Symbol string
+	Time   time.Time
+	Err    error
+}
+
+func (e *HeartbeatError) Error() string {
+	return fmt.Sprintf("countdown cancel all of %s not re-armed: %s", e.Symbol, e.Err)
+}
+
+func (e *HeartbeatError) Unwrap() error {
+	return e.Err
+}
+
+// dead man's switch, keeps re-arming the countdown of the symbols until Close
+type CancelHeartbeat struct {
+	client    *Client
+	symbols   []string
+	countdown time.Duration
+	interval  time.Duration
+	cancel    context.CancelFunc
+	done      chan struct{}
+	closeOnce sync.Once
+	errs      chan *HeartbeatError
+	mux       sync.RWMutex
+	lastArmed map[string]time.Time
+}
+
+// arm the countdown of every symbol now, then re-arm on the interval.
+// the interval should leave room for a few failed attempts within the countdown.
+func (c *Client) NewCancelHeartbeat(ctx context.Context, symbols []string, countdown, interval time.Duration) (*CancelHeartbeat, error) {
+	if len(symbols) == 0 {
+		return nil, errors.New("heartbeat without symbols")
+	}
+	if interval <= 0 || interval >= countdown {
+		return nil, errors.New("heartbeat interval should be positive and shorter than the countdown")
+	}
+	h := &CancelHeartbeat{
+		client:    c,
+		countdown: countdown,
+		interval:  interval,
+		done:      make(chan struct{}),
+		errs:      make(chan *HeartbeatError, 10),
+		lastArmed: make(map[string]time.Time, len(symbols)),
+	}
+	for _, symbol := range symbols {
+		h.symbols = append(h.symbols, strings.ToUpper(symbol))
+	}
+	for _, symbol := range h.symbols {
+		if err := h.arm(ctx, symbol); err != nil {
+			h.disarm()
+			return nil, err
+		}
+	}
+	beatCtx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+	go h.run(beatCtx)
+	return h, nil
+}
+
+// re-arm failures, the oldest are dropped when nobody reads them
+func (h *CancelHeartbeat) Errs() <-chan *HeartbeatError {
+	return h.errs
+}
+
+// false once any symbol went a whole countdown without a successful re-arm
+func (h *CancelHeartbeat) Healthy() bool {
+	h.mux.RLock()
+	defer h.mux.RUnlock()
+	now := time.Now()
+	for _, symbol := range h.symbols {
+		if now.Sub(h.lastArmed[symbol]) >= h.countdown {
+			return false
+		}
+	}
+	return true
+}
+
+// last successful arm of the symbol
+func (h *CancelHeartbeat) LastArmed(symbol string) time.Time {
+	h.mux.RLock()
+	defer h.mux.RUnlock()
+	return h.lastArmed[strings.ToUpper(symbol)]
+}
+
+// stop re-arming and disarm the countdowns, open orders are left alone
+func (h *CancelHeartbeat) Close() error {
+	var err error
+	h.closeOnce.Do(func() {
+		h.cancel()
+		<-h.done
+		err = h.disarm()
+	})
+	return err
+}
+
+// internal funcs ------------------------------------------------
+
+func (h *CancelHeartbeat) run(ctx context.Context) {
+	defer close(h.done)
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, symbol := range h.symbols {
+				if err := h.arm(ctx, symbol); err != nil {
+					if ctx.Err() != nil {
+						return
+					}
+					h.report(&HeartbeatError{Symbol: symbol, Time: time.Now(), Err: err})
+				}
+			}
+		}
+	}
+}
+
+func (h *CancelHeartbeat) arm(ctx context.Context, symbol string) error {
+	armCtx, cancel := context.WithTimeout(ctx, h.interval)
+	defer cancel()
+	if _, err := h.client.CountdownCancelAllWithContext(armCtx, symbol, h.countdown); err != nil {
+		return err
+	}
+	h.mux.Lock()
+	h.lastArmed[symbol] = time.Now()
+	h.mux.Unlock()
+	return nil
+}
+
+func (h *CancelHeartbeat) disarm() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var firstErr error
+	for _, symbol := range h.symbols {
+		if _, err := h.client.CountdownCancelAllWithContext(ctx, symbol, 0); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (h *CancelHeartbeat) report(err *HeartbeatError) {
+	for {
+		select {
+		case h.errs <- err:
+			return
+		default:
+		}
+		select {
+		case <-h.errs:
+		default:
+		}
+	}
+}
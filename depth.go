@@ -1,6 +1,7 @@
 package appolloxapi
 
 import (
+	"context"
 	"net/http"
 )
 
@@ -10,6 +11,10 @@ type DepthOpts struct {
 }
 
 func (b *Client) Depth(symbol string, limit int) (*Depth, error) {
+	return b.DepthWithContext(context.Background(), symbol, limit)
+}
+
+func (b *Client) DepthWithContext(ctx context.Context, symbol string, limit int) (*Depth, error) {
 	opts := DepthOpts{
 		Symbol: symbol,
 		Limit:  limit,
@@ -17,7 +22,7 @@ func (b *Client) Depth(symbol string, limit int) (*Depth, error) {
 	if opts.Limit == 0 || opts.Limit > 1000 {
 		opts.Limit = 100
 	}
-	res, err := b.do(http.MethodGet, "fapi/v1/depth", opts, false, false)
+	res, err := b.do(ctx, http.MethodGet, "fapi/v1/depth", opts, false, false)
 	if err != nil {
 		return nil, err
 	}
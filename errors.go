@@ -0,0 +1,116 @@
+package appolloxapi
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// common fapi error codes
+const (
+	CodeUnknown                    = -1000
+	CodeDisconnected               = -1001
+	CodeUnauthorized               = -1002
+	CodeTooManyRequests            = -1003
+	CodeUnexpectedResponse         = -1006
+	CodeTimeout                    = -1007
+	CodeTooManyOrders              = -1015
+	CodeTimestampOutsideRecvWindow = -1021
+	CodeInvalidSignature           = -1022
+	CodeIllegalChars               = -1100
+	CodeMandatoryParamMissing      = -1102
+	CodeInvalidTimeInForce         = -1115
+	CodeInvalidOrderType           = -1116
+	CodeInvalidSide                = -1117
+	CodeInvalidListenKey           = -1125
+	CodeNewOrderRejected           = -2010
+	CodeUnknownOrder               = -2011
+	CodeNoSuchOrder                = -2013
+	CodeBadAPIKeyFormat            = -2014
+	CodeRejectedAPIKey             = -2015
+	CodeMarginInsufficient         = -2019
+	CodeOrderWouldTrigger          = -2021
+	CodeReduceOnlyRejected         = -2022
+	CodePriceLessThanZero          = -4001
+	CodePriceGreaterThanMax        = -4002
+	CodeQtyLessThanZero            = -4003
+	CodeQtyLessThanMin             = -4004
+	CodeQtyGreaterThanMax          = -4005
+	CodeInvalidTickSize            = -4014
+	CodeInvalidStepSize            = -4023
+	CodeInvalidLeverage            = -4028
+	CodeNoNeedToChangeMarginType   = -4046
+	CodeMarginTypeWithOpenOrders   = -4047
+	CodeMarginTypeWithPosition     = -4048
+	CodeNoNeedToChangePosSide      = -4059
+	CodeInvalidPositionSide        = -4061
+	CodeDuplicateClientOrderID     = -4116
+	CodeMinNotional                = -4164
+	CodePostOnlyRejected           = -5022
+)
+
+// sentinels for errors.Is, only the code is compared
+var (
+	ErrTooManyRequests            = &APIError{Code: CodeTooManyRequests}
+	ErrTooManyOrders              = &APIError{Code: CodeTooManyOrders}
+	ErrTimestampOutsideRecvWindow = &APIError{Code: CodeTimestampOutsideRecvWindow}
+	ErrInvalidSignature           = &APIError{Code: CodeInvalidSignature}
+	ErrInvalidListenKey           = &APIError{Code: CodeInvalidListenKey}
+	ErrNewOrderRejected           = &APIError{Code: CodeNewOrderRejected}
+	ErrUnknownOrder               = &APIError{Code: CodeUnknownOrder}
+	ErrNoSuchOrder                = &APIError{Code: CodeNoSuchOrder}
+	ErrMarginInsufficient         = &APIError{Code: CodeMarginInsufficient}
+	ErrOrderWouldTrigger          = &APIError{Code: CodeOrderWouldTrigger}
+	ErrReduceOnlyRejected         = &APIError{Code: CodeReduceOnlyRejected}
+	ErrNoNeedToChangeMarginType   = &APIError{Code: CodeNoNeedToChangeMarginType}
+	ErrNoNeedToChangePosSide      = &APIError{Code: CodeNoNeedToChangePosSide}
+	ErrDuplicateClientOrderID     = &APIError{Code: CodeDuplicateClientOrderID}
+	ErrMinNotional                = &APIError{Code: CodeMinNotional}
+	ErrPostOnlyRejected           = &APIError{Code: CodePostOnlyRejected}
+)
+
+// APIError is returned for every rejected request, and for every failed item of the batch endpoints
+type APIError struct {
+	StatusCode int    `json:"-"`
+	Method     string `json:"-"`
+	Endpoint   string `json:"-"`
+	Code       int    `json:"code"`
+	Msg        string `json:"msg"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("apx %s: status %d, code %d: %s", e.Endpoint, e.StatusCode, e.Code, e.Msg)
+}
+
+// errors.Is(err, ErrUnknownOrder) matches on the exchange code
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	if !ok {
+		return false
+	}
+	return t.Code == e.Code
+}
+
+func newAPIError(statusCode int, method, endpoint string, body []byte) *APIError {
+	apiErr := &APIError{}
+	if err := json.Unmarshal(body, apiErr); err != nil || (apiErr.Code == 0 && apiErr.Msg == "") {
+		apiErr.Code = 0
+		apiErr.Msg = string(body)
+	}
+	apiErr.StatusCode = statusCode
+	apiErr.Method = method
+	apiErr.Endpoint = endpoint
+	return apiErr
+}
+
+// Err returns the per-item error of a batch request, nil when the item succeeded
+func (r *BatchOrderResult) Err() error {
+	if r.Code == 0 {
+		return nil
+	}
+	return &APIError{
+		StatusCode: http.StatusOK,
+		Endpoint:   "fapi/v1/batchOrders",
+		Code:       r.Code,
+		Msg:        r.Msg,
+	}
+}
@@ -1,68 +1,83 @@
 package appolloxapi
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 )
 
 type GetExchangeInfoResponse struct {
-	Timezone    string `json:"timezone"`
-	ServerTime  int64  `json:"serverTime"`
-	FuturesType string `json:"futuresType"`
-	RateLimits  []struct {
-		RateLimitType string `json:"rateLimitType"`
-		Interval      string `json:"interval"`
-		IntervalNum   int    `json:"intervalNum"`
-		Limit         int    `json:"limit"`
-	} `json:"rateLimits"`
+	Timezone        string        `json:"timezone"`
+	ServerTime      int64         `json:"serverTime"`
+	FuturesType     string        `json:"futuresType"`
+	RateLimits      []RateLimit   `json:"rateLimits"`
 	ExchangeFilters []interface{} `json:"exchangeFilters"`
-	Assets          []struct {
-		Asset             string `json:"asset"`
-		MarginAvailable   bool   `json:"marginAvailable"`
-		AutoAssetExchange string `json:"autoAssetExchange"`
-	} `json:"assets"`
-	Symbols []struct {
-		Symbol                string   `json:"symbol"`
-		Pair                  string   `json:"pair"`
-		ContractType          string   `json:"contractType"`
-		DeliveryDate          int64    `json:"deliveryDate"`
-		OnboardDate           int64    `json:"onboardDate"`
-		Status                string   `json:"status"`
-		MaintMarginPercent    string   `json:"maintMarginPercent"`
-		RequiredMarginPercent string   `json:"requiredMarginPercent"`
-		BaseAsset             string   `json:"baseAsset"`
-		QuoteAsset            string   `json:"quoteAsset"`
-		MarginAsset           string   `json:"marginAsset"`
-		PricePrecision        int      `json:"pricePrecision"`
-		QuantityPrecision     int      `json:"quantityPrecision"`
-		BaseAssetPrecision    int      `json:"baseAssetPrecision"`
-		QuotePrecision        int      `json:"quotePrecision"`
-		UnderlyingType        string   `json:"underlyingType"`
-		UnderlyingSubType     []string `json:"underlyingSubType"`
-		SettlePlan            int      `json:"settlePlan"`
-		TriggerProtect        string   `json:"triggerProtect"`
-		LiquidationFee        string   `json:"liquidationFee"`
-		MarketTakeBound       string   `json:"marketTakeBound"`
-		Filters               []struct {
-			MinPrice          string `json:"minPrice,omitempty"`
-			MaxPrice          string `json:"maxPrice,omitempty"`
-			FilterType        string `json:"filterType"`
-			TickSize          string `json:"tickSize,omitempty"`
-			StepSize          string `json:"stepSize,omitempty"`
-			MaxQty            string `json:"maxQty,omitempty"`
-			MinQty            string `json:"minQty,omitempty"`
-			Limit             int    `json:"limit,omitempty"`
-			Notional          string `json:"notional,omitempty"`
-			MultiplierDown    string `json:"multiplierDown,omitempty"`
-			MultiplierUp      string `json:"multiplierUp,omitempty"`
-			MultiplierDecimal string `json:"multiplierDecimal,omitempty"`
-		} `json:"filters"`
-		OrderTypes  []string `json:"orderTypes"`
-		TimeInForce []string `json:"timeInForce"`
-	} `json:"symbols"`
+	Assets          []AssetInfo   `json:"assets"`
+	Symbols         []SymbolInfo  `json:"symbols"`
+}
+
+type AssetInfo struct {
+	Asset             string `json:"asset"`
+	MarginAvailable   bool   `json:"marginAvailable"`
+	AutoAssetExchange string `json:"autoAssetExchange"`
+}
+
+type SymbolInfo struct {
+	Symbol                string         `json:"symbol"`
+	Pair                  string         `json:"pair"`
+	ContractType          string         `json:"contractType"`
+	DeliveryDate          int64          `json:"deliveryDate"`
+	OnboardDate           int64          `json:"onboardDate"`
+	Status                string         `json:"status"`
+	MaintMarginPercent    string         `json:"maintMarginPercent"`
+	RequiredMarginPercent string         `json:"requiredMarginPercent"`
+	BaseAsset             string         `json:"baseAsset"`
+	QuoteAsset            string         `json:"quoteAsset"`
+	MarginAsset           string         `json:"marginAsset"`
+	PricePrecision        int            `json:"pricePrecision"`
+	QuantityPrecision     int            `json:"quantityPrecision"`
+	BaseAssetPrecision    int            `json:"baseAssetPrecision"`
+	QuotePrecision        int            `json:"quotePrecision"`
+	UnderlyingType        string         `json:"underlyingType"`
+	UnderlyingSubType     []string       `json:"underlyingSubType"`
+	SettlePlan            int            `json:"settlePlan"`
+	TriggerProtect        string         `json:"triggerProtect"`
+	LiquidationFee        string         `json:"liquidationFee"`
+	MarketTakeBound       string         `json:"marketTakeBound"`
+	Filters               []SymbolFilter `json:"filters"`
+	OrderTypes            []string       `json:"orderTypes"`
+	TimeInForce           []string       `json:"timeInForce"`
+}
+
+// raw filter, every filter type fills its own fields, see Rules for the typed ones
+type SymbolFilter struct {
+	MinPrice          string `json:"minPrice,omitempty"`
+	MaxPrice          string `json:"maxPrice,omitempty"`
+	FilterType        string `json:"filterType"`
+	TickSize          string `json:"tickSize,omitempty"`
+	StepSize          string `json:"stepSize,omitempty"`
+	MaxQty            string `json:"maxQty,omitempty"`
+	MinQty            string `json:"minQty,omitempty"`
+	Limit             int    `json:"limit,omitempty"`
+	Notional          string `json:"notional,omitempty"`
+	MultiplierDown    string `json:"multiplierDown,omitempty"`
+	MultiplierUp      string `json:"multiplierUp,omitempty"`
+	MultiplierDecimal string `json:"multiplierDecimal,omitempty"`
+}
+
+type RateLimit struct {
+	RateLimitType string `json:"rateLimitType"`
+	Interval      string `json:"interval"`
+	IntervalNum   int    `json:"intervalNum"`
+	Limit         int    `json:"limit"`
 }
 
 func (b *Client) GetExchangeInfo() (*GetExchangeInfoResponse, error) {
-	res, err := b.do(http.MethodGet, "fapi/v1/exchangeInfo", nil, false, false)
+	return b.GetExchangeInfoWithContext(context.Background())
+}
+
+func (b *Client) GetExchangeInfoWithContext(ctx context.Context) (*GetExchangeInfoResponse, error) {
+	res, err := b.do(ctx, http.MethodGet, "fapi/v1/exchangeInfo", nil, false, false)
 	if err != nil {
 		return nil, err
 	}
@@ -73,3 +88,12 @@ func (b *Client) GetExchangeInfo() (*GetExchangeInfoResponse, error) {
 	}
 	return exchange, nil
 }
+
+func (e *GetExchangeInfoResponse) Rules(symbol string) (*SymbolRules, error) {
+	for _, info := range e.Symbols {
+		if info.Symbol == symbol {
+			return info.Rules()
+		}
+	}
+	return nil, fmt.Errorf("symbol %s not found in exchange info", symbol)
+}
@@ -0,0 +1,376 @@
+package appolloxapi
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	SymbolStatusTrading  = "TRADING"
+	SymbolStatusSettling = "SETTLING"
+	SymbolStatusClose    = "CLOSE"
+)
+
+type ExchangeInfoEventType string
+
+const (
+	SymbolOnboarded           ExchangeInfoEventType = "SYMBOL_ONBOARDED"
+	SymbolRemoved             ExchangeInfoEventType = "SYMBOL_REMOVED"
+	SymbolStatusChanged       ExchangeInfoEventType = "STATUS_CHANGED"
+	SymbolFiltersChanged      ExchangeInfoEventType = "FILTERS_CHANGED"
+	SymbolDeliveryDateChanged ExchangeInfoEventType = "DELIVERY_DATE_CHANGED"
+)
+
+// Old is nil when onboarded, New is nil when removed
+type ExchangeInfoEvent struct {
+	Type   ExchangeInfoEventType
+	Symbol string
+	Old    *SymbolInfo
+	New    *SymbolInfo
+}
+
+// contract metadata of a symbol in typed form
+type ContractInfo struct {
+	Symbol                string
+	Pair                  string
+	ContractType          string
+	Status                string
+	BaseAsset             string
+	QuoteAsset            string
+	MarginAsset           string
+	DeliveryDate          time.Time
+	OnboardDate           time.Time
+	MaintMarginPercent    decimal.Decimal
+	RequiredMarginPercent decimal.Decimal
+	LiquidationFee        decimal.Decimal
+	MarketTakeBound       decimal.Decimal
+	TriggerProtect        decimal.Decimal
+}
+
+// OnChange handlers run on the refresh goroutine in registration order, a slow one delays the next refresh.
+// the getters return copies, changing them leaves the cache alone.
+type ExchangeInfoCache struct {
+	client      *Client
+	cancel      context.CancelFunc
+	refreshMux  sync.Mutex
+	mux         sync.RWMutex
+	symbols     map[string]*SymbolInfo
+	rules       map[string]*SymbolRules
+	ruleErrs    map[string]error
+	assets      map[string]AssetInfo
+	lastRefresh time.Time
+	lastErr     error
+	handlers    exchangeInfoHandlers
+}
+
+type exchangeInfoHandlers struct {
+	mux  sync.RWMutex
+	list []func(ExchangeInfoEvent)
+}
+
+// load the exchange info once, then refresh it on the interval until Close
+func (c *Client) NewExchangeInfoCache(ctx context.Context, interval time.Duration) (*ExchangeInfoCache, error) {
+	if interval <= 0 {
+		return nil, errors.New("exchange info refresh interval should be positive")
+	}
+	e := &ExchangeInfoCache{
+		client:  c,
+		symbols: make(map[string]*SymbolInfo),
+		rules:   make(map[string]*SymbolRules),
+		assets:  make(map[string]AssetInfo),
+	}
+	if err := e.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	refreshCtx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-refreshCtx.Done():
+				return
+			case <-ticker.C:
+				// kept in LastRefresh
+				e.Refresh(refreshCtx)
+			}
+		}
+	}()
+	return e, nil
+}
+
+func (e *ExchangeInfoCache) Close() {
+	if e.cancel != nil {
+		e.cancel()
+	}
+}
+
+// symbol changes found by a refresh, not the first load
+func (e *ExchangeInfoCache) OnChange(handler func(ExchangeInfoEvent)) {
+	e.handlers.mux.Lock()
+	defer e.handlers.mux.Unlock()
+	e.handlers.list = append(e.handlers.list, handler)
+}
+
+// fetch now and emit the changes since the last refresh
+func (e *ExchangeInfoCache) Refresh(ctx context.Context) error {
+	e.refreshMux.Lock()
+	defer e.refreshMux.Unlock()
+	info, err := e.client.GetExchangeInfoWithContext(ctx)
+	if err != nil {
+		e.mux.Lock()
+		e.lastErr = err
+		e.mux.Unlock()
+		return err
+	}
+	e.client.SetRateLimits(info.RateLimits)
+	symbols := make(map[string]*SymbolInfo, len(info.Symbols))
+	rules := make(map[string]*SymbolRules, len(info.Symbols))
+	ruleErrs := make(map[string]error)
+	for i := range info.Symbols {
+		symbol := &info.Symbols[i]
+		symbols[symbol.Symbol] = symbol
+		r, err := symbol.Rules()
+		if err != nil {
+			ruleErrs[symbol.Symbol] = err
+			continue
+		}
+		rules[symbol.Symbol] = r
+	}
+	assets := make(map[string]AssetInfo, len(info.Assets))
+	for _, asset := range info.Assets {
+		assets[asset.Asset] = asset
+	}
+	e.mux.Lock()
+	old := e.symbols
+	first := e.lastRefresh.IsZero()
+	e.symbols = symbols
+	e.rules = rules
+	e.ruleErrs = ruleErrs
+	e.assets = assets
+	e.lastRefresh = time.Now()
+	e.lastErr = nil
+	e.mux.Unlock()
+	if first {
+		return nil
+	}
+	for _, event := range diffSymbols(old, symbols) {
+		e.emit(event)
+	}
+	return nil
+}
+
+// time of the last successful refresh, and the error of the last attempt
+func (e *ExchangeInfoCache) LastRefresh() (time.Time, error) {
+	e.mux.RLock()
+	defer e.mux.RUnlock()
+	return e.lastRefresh, e.lastErr
+}
+
+func (e *ExchangeInfoCache) Symbol(symbol string) (SymbolInfo, bool) {
+	e.mux.RLock()
+	defer e.mux.RUnlock()
+	info, ok := e.symbols[symbol]
+	if !ok {
+		return SymbolInfo{}, false
+	}
+	return *info.clone(), true
+}
+
+// sorted symbol names
+func (e *ExchangeInfoCache) Symbols() []string {
+	e.mux.RLock()
+	defer e.mux.RUnlock()
+	list := make([]string, 0, len(e.symbols))
+	for symbol := range e.symbols {
+		list = append(list, symbol)
+	}
+	sort.Strings(list)
+	return list
+}
+
+func (e *ExchangeInfoCache) Asset(asset string) (AssetInfo, bool) {
+	e.mux.RLock()
+	defer e.mux.RUnlock()
+	info, ok := e.assets[asset]
+	return info, ok
+}
+
+// false for unknown symbols and for filters that failed to parse, see RulesError
+func (e *ExchangeInfoCache) Rules(symbol string) (*SymbolRules, bool) {
+	e.mux.RLock()
+	defer e.mux.RUnlock()
+	r, ok := e.rules[symbol]
+	if !ok {
+		return nil, false
+	}
+	return r.clone(), true
+}
+
+// why the filters of the symbol failed to parse in the last refresh, nil otherwise
+func (e *ExchangeInfoCache) RulesError(symbol string) error {
+	e.mux.RLock()
+	defer e.mux.RUnlock()
+	return e.ruleErrs[symbol]
+}
+
+// false for unknown symbols too
+func (e *ExchangeInfoCache) IsTrading(symbol string) bool {
+	e.mux.RLock()
+	defer e.mux.RUnlock()
+	info, ok := e.symbols[symbol]
+	return ok && info.Status == SymbolStatusTrading
+}
+
+func (e *ExchangeInfoCache) PricePrecision(symbol string) (int, bool) {
+	e.mux.RLock()
+	defer e.mux.RUnlock()
+	info, ok := e.symbols[symbol]
+	if !ok {
+		return 0, false
+	}
+	return info.PricePrecision, true
+}
+
+func (e *ExchangeInfoCache) QuantityPrecision(symbol string) (int, bool) {
+	e.mux.RLock()
+	defer e.mux.RUnlock()
+	info, ok := e.symbols[symbol]
+	if !ok {
+		return 0, false
+	}
+	return info.QuantityPrecision, true
+}
+
+func (e *ExchangeInfoCache) Contract(symbol string) (ContractInfo, bool) {
+	e.mux.RLock()
+	defer e.mux.RUnlock()
+	info, ok := e.symbols[symbol]
+	if !ok {
+		return ContractInfo{}, false
+	}
+	return info.Contract(), true
+}
+
+// unparsable numbers are left as zero
+func (s SymbolInfo) Contract() ContractInfo {
+	contract := ContractInfo{
+		Symbol:       s.Symbol,
+		Pair:         s.Pair,
+		ContractType: s.ContractType,
+		Status:       s.Status,
+		BaseAsset:    s.BaseAsset,
+		QuoteAsset:   s.QuoteAsset,
+		MarginAsset:  s.MarginAsset,
+		DeliveryDate: time.Unix(0, s.DeliveryDate*int64(time.Millisecond)),
+		OnboardDate:  time.Unix(0, s.OnboardDate*int64(time.Millisecond)),
+	}
+	parseDecimals(
+		s.MaintMarginPercent, &contract.MaintMarginPercent,
+		s.RequiredMarginPercent, &contract.RequiredMarginPercent,
+		s.LiquidationFee, &contract.LiquidationFee,
+		s.MarketTakeBound, &contract.MarketTakeBound,
+		s.TriggerProtect, &contract.TriggerProtect,
+	)
+	return contract
+}
+
+// internal funcs ------------------------------------------------
+
+func (s *SymbolInfo) clone() *SymbolInfo {
+	if s == nil {
+		return nil
+	}
+	info := *s
+	info.UnderlyingSubType = append([]string(nil), s.UnderlyingSubType...)
+	info.Filters = append([]SymbolFilter(nil), s.Filters...)
+	info.OrderTypes = append([]string(nil), s.OrderTypes...)
+	info.TimeInForce = append([]string(nil), s.TimeInForce...)
+	return &info
+}
+
+func (r *SymbolRules) clone() *SymbolRules {
+	rules := *r
+	if r.Price != nil {
+		price := *r.Price
+		rules.Price = &price
+	}
+	if r.LotSize != nil {
+		lot := *r.LotSize
+		rules.LotSize = &lot
+	}
+	if r.MarketLotSize != nil {
+		lot := *r.MarketLotSize
+		rules.MarketLotSize = &lot
+	}
+	if r.MinNotional != nil {
+		notional := *r.MinNotional
+		rules.MinNotional = &notional
+	}
+	if r.PercentPrice != nil {
+		percent := *r.PercentPrice
+		rules.PercentPrice = &percent
+	}
+	if r.MaxNumOrders != nil {
+		limit := *r.MaxNumOrders
+		rules.MaxNumOrders = &limit
+	}
+	if r.MaxNumAlgoOrders != nil {
+		limit := *r.MaxNumAlgoOrders
+		rules.MaxNumAlgoOrders = &limit
+	}
+	return &rules
+}
+
+func (e *ExchangeInfoCache) emit(event ExchangeInfoEvent) {
+	e.handlers.mux.RLock()
+	defer e.handlers.mux.RUnlock()
+	for _, handler := range e.handlers.list {
+		// every handler gets its own copy
+		handler(ExchangeInfoEvent{Type: event.Type, Symbol: event.Symbol, Old: event.Old.clone(), New: event.New.clone()})
+	}
+}
+
+func diffSymbols(prev, next map[string]*SymbolInfo) []ExchangeInfoEvent {
+	events := []ExchangeInfoEvent{}
+	names := make([]string, 0, len(next))
+	for symbol := range next {
+		names = append(names, symbol)
+	}
+	sort.Strings(names)
+	for _, symbol := range names {
+		after := next[symbol]
+		before, ok := prev[symbol]
+		if !ok {
+			events = append(events, ExchangeInfoEvent{Type: SymbolOnboarded, Symbol: symbol, New: after})
+			continue
+		}
+		if before.Status != after.Status {
+			events = append(events, ExchangeInfoEvent{Type: SymbolStatusChanged, Symbol: symbol, Old: before, New: after})
+		}
+		if !reflect.DeepEqual(before.Filters, after.Filters) {
+			events = append(events, ExchangeInfoEvent{Type: SymbolFiltersChanged, Symbol: symbol, Old: before, New: after})
+		}
+		if before.DeliveryDate != after.DeliveryDate {
+			events = append(events, ExchangeInfoEvent{Type: SymbolDeliveryDateChanged, Symbol: symbol, Old: before, New: after})
+		}
+	}
+	removed := []string{}
+	for symbol := range prev {
+		if _, ok := next[symbol]; !ok {
+			removed = append(removed, symbol)
+		}
+	}
+	sort.Strings(removed)
+	for _, symbol := range removed {
+		events = append(events, ExchangeInfoEvent{Type: SymbolRemoved, Symbol: symbol, Old: prev[symbol]})
+	}
+	return events
+}
@@ -0,0 +1,329 @@
+package appolloxapi
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	FilterPrice            = "PRICE_FILTER"
+	FilterLotSize          = "LOT_SIZE"
+	FilterMarketLotSize    = "MARKET_LOT_SIZE"
+	FilterMinNotional      = "MIN_NOTIONAL"
+	FilterPercentPrice     = "PERCENT_PRICE"
+	FilterMaxNumOrders     = "MAX_NUM_ORDERS"
+	FilterMaxNumAlgoOrders = "MAX_NUM_ALGO_ORDERS"
+)
+
+type PriceFilter struct {
+	MinPrice decimal.Decimal
+	MaxPrice decimal.Decimal
+	TickSize decimal.Decimal
+}
+
+// LOT_SIZE and MARKET_LOT_SIZE
+type LotSizeFilter struct {
+	MinQty   decimal.Decimal
+	MaxQty   decimal.Decimal
+	StepSize decimal.Decimal
+}
+
+type MinNotionalFilter struct {
+	Notional decimal.Decimal
+}
+
+// buy price should be at most mark price * multiplierUp, sell price at least mark price * multiplierDown
+type PercentPriceFilter struct {
+	MultiplierUp      decimal.Decimal
+	MultiplierDown    decimal.Decimal
+	MultiplierDecimal int
+}
+
+// MAX_NUM_ORDERS and MAX_NUM_ALGO_ORDERS
+type MaxNumOrdersFilter struct {
+	Limit int
+}
+
+// nil filters are not set on the symbol
+type SymbolRules struct {
+	Symbol            string
+	PricePrecision    int
+	QuantityPrecision int
+	Price             *PriceFilter
+	LotSize           *LotSizeFilter
+	MarketLotSize     *LotSizeFilter
+	MinNotional       *MinNotionalFilter
+	PercentPrice      *PercentPriceFilter
+	MaxNumOrders      *MaxNumOrdersFilter
+	MaxNumAlgoOrders  *MaxNumOrdersFilter
+}
+
+var ErrFilterViolation = errors.New("order violates symbol filter")
+
+// tells which filter the order would break, errors.Is(err, ErrFilterViolation) is true
+type FilterError struct {
+	Symbol string
+	Filter string
+	Field  string
+	Value  string
+	Reason string
+}
+
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("%s %s: %s %s %s", e.Symbol, e.Filter, e.Field, e.Value, e.Reason)
+}
+
+func (e *FilterError) Is(target error) bool {
+	return target == ErrFilterViolation
+}
+
+func (s SymbolInfo) Rules() (*SymbolRules, error) {
+	rules := &SymbolRules{
+		Symbol:            s.Symbol,
+		PricePrecision:    s.PricePrecision,
+		QuantityPrecision: s.QuantityPrecision,
+	}
+	for _, filter := range s.Filters {
+		var err error
+		switch filter.FilterType {
+		case FilterPrice:
+			rules.Price = &PriceFilter{}
+			err = parseDecimals(
+				filter.MinPrice, &rules.Price.MinPrice,
+				filter.MaxPrice, &rules.Price.MaxPrice,
+				filter.TickSize, &rules.Price.TickSize,
+			)
+		case FilterLotSize:
+			rules.LotSize = &LotSizeFilter{}
+			err = rules.LotSize.parse(filter)
+		case FilterMarketLotSize:
+			rules.MarketLotSize = &LotSizeFilter{}
+			err = rules.MarketLotSize.parse(filter)
+		case FilterMinNotional:
+			rules.MinNotional = &MinNotionalFilter{}
+			err = parseDecimals(filter.Notional, &rules.MinNotional.Notional)
+		case FilterPercentPrice:
+			rules.PercentPrice = &PercentPriceFilter{}
+			err = parseDecimals(
+				filter.MultiplierUp, &rules.PercentPrice.MultiplierUp,
+				filter.MultiplierDown, &rules.PercentPrice.MultiplierDown,
+			)
+			if err == nil && filter.MultiplierDecimal != "" {
+				rules.PercentPrice.MultiplierDecimal, err = strconv.Atoi(filter.MultiplierDecimal)
+			}
+		case FilterMaxNumOrders:
+			rules.MaxNumOrders = &MaxNumOrdersFilter{Limit: filter.Limit}
+		case FilterMaxNumAlgoOrders:
+			rules.MaxNumAlgoOrders = &MaxNumOrdersFilter{Limit: filter.Limit}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", s.Symbol, filter.FilterType, err)
+		}
+	}
+	return rules, nil
+}
+
+// bids round down and asks round up to the tick size, so a quote never crosses the intended price
+func (r *SymbolRules) RoundPrice(side string, price decimal.Decimal) decimal.Decimal {
+	if r.Price == nil || !r.Price.TickSize.IsPositive() {
+		return price
+	}
+	switch strings.ToUpper(side) {
+	case "SELL", "ASK":
+		return roundUpToStep(price, r.Price.TickSize)
+	default:
+		return roundDownToStep(price, r.Price.TickSize)
+	}
+}
+
+// round down to the step size of LOT_SIZE, or of MARKET_LOT_SIZE for market orders
+func (r *SymbolRules) RoundQty(qty decimal.Decimal, market bool) decimal.Decimal {
+	lot := r.lotSize(market)
+	if lot == nil || !lot.StepSize.IsPositive() {
+		return qty
+	}
+	return roundDownToStep(qty, lot.StepSize)
+}
+
+// quantity worth about the notional at the price, rounded down to the step size
+func (r *SymbolRules) QtyForNotional(notional, price decimal.Decimal, market bool) decimal.Decimal {
+	if !price.IsPositive() {
+		return decimal.Zero
+	}
+	return r.RoundQty(notional.Div(price), market)
+}
+
+// check price, quantity and notional against the filters.
+// market orders carry no price, so MIN_NOTIONAL and PERCENT_PRICE are only checked by ValidateAt.
+func (r *SymbolRules) Validate(order PlaceOrderOpts) error {
+	return r.validate(order, decimal.Zero)
+}
+
+// same as Validate, plus the checks needing the current mark price
+func (r *SymbolRules) ValidateAt(order PlaceOrderOpts, markPrice decimal.Decimal) error {
+	return r.validate(order, markPrice)
+}
+
+// internal funcs ------------------------------------------------
+
+func (r *SymbolRules) validate(order PlaceOrderOpts, markPrice decimal.Decimal) error {
+	order.normalize()
+	market := isMarketOrderType(order.Type)
+	var price, qty decimal.Decimal
+	var err error
+	if order.Price != "" {
+		if price, err = decimal.NewFromString(order.Price); err != nil {
+			return invalidOrder("price %q is not a number", order.Price)
+		}
+		if err := r.checkPrice("price", price); err != nil {
+			return err
+		}
+	}
+	if order.StopPrice != "" {
+		stop, err := decimal.NewFromString(order.StopPrice)
+		if err != nil {
+			return invalidOrder("stopPrice %q is not a number", order.StopPrice)
+		}
+		if err := r.checkPrice("stopPrice", stop); err != nil {
+			return err
+		}
+	}
+	if order.Qty != "" {
+		if qty, err = decimal.NewFromString(order.Qty); err != nil {
+			return invalidOrder("quantity %q is not a number", order.Qty)
+		}
+		if err := r.checkQty(qty, market); err != nil {
+			return err
+		}
+	}
+	// buys are only capped above and sells only below
+	if r.PercentPrice != nil && markPrice.IsPositive() && !price.IsZero() {
+		switch order.Side {
+		case "BUY":
+			up := markPrice.Mul(r.PercentPrice.MultiplierUp)
+			if price.GreaterThan(up) {
+				return r.violation(FilterPercentPrice, "price", price, "above "+up.String())
+			}
+		case "SELL":
+			down := markPrice.Mul(r.PercentPrice.MultiplierDown)
+			if price.LessThan(down) {
+				return r.violation(FilterPercentPrice, "price", price, "below "+down.String())
+			}
+		}
+	}
+	// reduce only orders are exempted from min notional
+	if r.MinNotional != nil && !qty.IsZero() && order.ReduceOnly != "true" {
+		ref := price
+		if ref.IsZero() {
+			ref = markPrice
+		}
+		if ref.IsPositive() {
+			notional := ref.Mul(qty)
+			if notional.LessThan(r.MinNotional.Notional) {
+				return r.violation(FilterMinNotional, "notional", notional, "below "+r.MinNotional.Notional.String())
+			}
+		}
+	}
+	return nil
+}
+
+func (r *SymbolRules) checkPrice(field string, price decimal.Decimal) error {
+	if r.Price == nil {
+		return nil
+	}
+	f := r.Price
+	if f.MinPrice.IsPositive() && price.LessThan(f.MinPrice) {
+		return r.violation(FilterPrice, field, price, "below min "+f.MinPrice.String())
+	}
+	if f.MaxPrice.IsPositive() && price.GreaterThan(f.MaxPrice) {
+		return r.violation(FilterPrice, field, price, "above max "+f.MaxPrice.String())
+	}
+	if f.TickSize.IsPositive() && !price.Sub(f.MinPrice).Mod(f.TickSize).IsZero() {
+		return r.violation(FilterPrice, field, price, "not a multiple of tick size "+f.TickSize.String())
+	}
+	return nil
+}
+
+func (r *SymbolRules) checkQty(qty decimal.Decimal, market bool) error {
+	lot := r.lotSize(market)
+	if lot == nil {
+		return nil
+	}
+	filter := FilterLotSize
+	if market && r.MarketLotSize != nil {
+		filter = FilterMarketLotSize
+	}
+	if qty.LessThan(lot.MinQty) {
+		return r.violation(filter, "quantity", qty, "below min "+lot.MinQty.String())
+	}
+	if lot.MaxQty.IsPositive() && qty.GreaterThan(lot.MaxQty) {
+		return r.violation(filter, "quantity", qty, "above max "+lot.MaxQty.String())
+	}
+	if lot.StepSize.IsPositive() && !qty.Sub(lot.MinQty).Mod(lot.StepSize).IsZero() {
+		return r.violation(filter, "quantity", qty, "not a multiple of step size "+lot.StepSize.String())
+	}
+	return nil
+}
+
+// market orders fall back to LOT_SIZE when MARKET_LOT_SIZE is missing
+func (r *SymbolRules) lotSize(market bool) *LotSizeFilter {
+	if market && r.MarketLotSize != nil {
+		return r.MarketLotSize
+	}
+	return r.LotSize
+}
+
+func (r *SymbolRules) violation(filter, field string, value decimal.Decimal, reason string) error {
+	return &FilterError{
+		Symbol: r.Symbol,
+		Filter: filter,
+		Field:  field,
+		Value:  value.String(),
+		Reason: reason,
+	}
+}
+
+func (l *LotSizeFilter) parse(filter SymbolFilter) error {
+	return parseDecimals(
+		filter.MinQty, &l.MinQty,
+		filter.MaxQty, &l.MaxQty,
+		filter.StepSize, &l.StepSize,
+	)
+}
+
+// pairs of raw string and destination, empty strings are left as zero
+func parseDecimals(pairs ...interface{}) error {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		raw, _ := pairs[i].(string)
+		dst, _ := pairs[i+1].(*decimal.Decimal)
+		if raw == "" || dst == nil {
+			continue
+		}
+		value, err := decimal.NewFromString(raw)
+		if err != nil {
+			return err
+		}
+		*dst = value
+	}
+	return nil
+}
+
+func isMarketOrderType(orderType string) bool {
+	switch orderType {
+	case OrderTypeMarket, OrderTypeStopMarket, OrderTypeTakeProfitMarket, OrderTypeTrailingStopMarket:
+		return true
+	}
+	return false
+}
+
+func roundDownToStep(value, step decimal.Decimal) decimal.Decimal {
+	return value.Div(step).Floor().Mul(step)
+}
+
+func roundUpToStep(value, step decimal.Decimal) decimal.Decimal {
+	return value.Div(step).Ceil().Mul(step)
+}
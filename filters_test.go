@@ -0,0 +1,83 @@
+package appolloxapi
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func testRules() *SymbolRules {
+	d := decimal.RequireFromString
+	return &SymbolRules{
+		Symbol:        "BTCUSDT",
+		Price:         &PriceFilter{MinPrice: d("0.1"), MaxPrice: d("100000"), TickSize: d("0.1")},
+		LotSize:       &LotSizeFilter{MinQty: d("0.001"), MaxQty: d("1000"), StepSize: d("0.001")},
+		MarketLotSize: &LotSizeFilter{MinQty: d("0.001"), MaxQty: d("100"), StepSize: d("0.001")},
+		MinNotional:   &MinNotionalFilter{Notional: d("5")},
+		PercentPrice:  &PercentPriceFilter{MultiplierUp: d("1.05"), MultiplierDown: d("0.95")},
+	}
+}
+
+func TestValidateAt(t *testing.T) {
+	mark := decimal.NewFromInt(100)
+	cases := []struct {
+		name   string
+		order  PlaceOrderOpts
+		filter string
+	}{
+		{"buy far below mark", PlaceOrderOpts{Side: "BUY", Type: OrderTypeLimit, Price: "80", Qty: "1"}, ""},
+		{"buy above up bound", PlaceOrderOpts{Side: "BUY", Type: OrderTypeLimit, Price: "106", Qty: "1"}, FilterPercentPrice},
+		{"buy at up bound", PlaceOrderOpts{Side: "buy", Type: OrderTypeLimit, Price: "105", Qty: "1"}, ""},
+		{"sell far above mark", PlaceOrderOpts{Side: "SELL", Type: OrderTypeLimit, Price: "120", Qty: "1"}, ""},
+		{"sell below down bound", PlaceOrderOpts{Side: "SELL", Type: OrderTypeLimit, Price: "94", Qty: "1"}, FilterPercentPrice},
+		{"sell at down bound", PlaceOrderOpts{Side: "sell", Type: OrderTypeLimit, Price: "95", Qty: "1"}, ""},
+		{"off tick", PlaceOrderOpts{Side: "BUY", Type: OrderTypeLimit, Price: "100.05", Qty: "1"}, FilterPrice},
+		{"off step", PlaceOrderOpts{Side: "BUY", Type: OrderTypeLimit, Price: "100", Qty: "0.0015"}, FilterLotSize},
+		{"market above market max", PlaceOrderOpts{Side: "BUY", Type: OrderTypeMarket, Qty: "200"}, FilterMarketLotSize},
+		{"small notional", PlaceOrderOpts{Side: "BUY", Type: OrderTypeLimit, Price: "100", Qty: "0.01"}, FilterMinNotional},
+		{"small market notional at mark", PlaceOrderOpts{Side: "SELL", Type: OrderTypeMarket, Qty: "0.01"}, FilterMinNotional},
+		{"small reduce only", PlaceOrderOpts{Side: "SELL", Type: OrderTypeMarket, Qty: "0.01", ReduceOnly: "true"}, ""},
+	}
+	rules := testRules()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.order.Symbol = "BTCUSDT"
+			err := rules.ValidateAt(tc.order, mark)
+			if tc.filter == "" {
+				if err != nil {
+					t.Fatalf("want no error, got %s", err)
+				}
+				return
+			}
+			var filterErr *FilterError
+			if !errors.As(err, &filterErr) || !errors.Is(err, ErrFilterViolation) {
+				t.Fatalf("want a filter error, got %v", err)
+			}
+			if filterErr.Filter != tc.filter {
+				t.Fatalf("filter %s, want %s: %s", filterErr.Filter, tc.filter, err)
+			}
+		})
+	}
+}
+
+func TestValidateSkipsMarkPriceChecks(t *testing.T) {
+	order := PlaceOrderOpts{Symbol: "BTCUSDT", Side: "BUY", Type: OrderTypeLimit, Price: "1000", Qty: "1"}
+	if err := testRules().Validate(order); err != nil {
+		t.Fatalf("want no error without a mark price, got %s", err)
+	}
+}
+
+func TestRoundPriceAndQty(t *testing.T) {
+	rules := testRules()
+	d := decimal.RequireFromString
+	if got := rules.RoundPrice("BUY", d("100.07")); !got.Equal(d("100")) {
+		t.Fatalf("buy price %s, want 100", got)
+	}
+	if got := rules.RoundPrice("SELL", d("100.01")); !got.Equal(d("100.1")) {
+		t.Fatalf("sell price %s, want 100.1", got)
+	}
+	if got := rules.RoundQty(d("0.0019"), false); !got.Equal(d("0.001")) {
+		t.Fatalf("qty %s, want 0.001", got)
+	}
+}
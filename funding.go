@@ -1,8 +1,15 @@
 package appolloxapi
 
-import "net/http"
+import (
+	"context"
+	"net/http"
+)
 
 func (b *Client) FundingRateHistory(symbol string, limit int, start, end int64) ([]*FundingData, error) {
+	return b.FundingRateHistoryWithContext(context.Background(), symbol, limit, start, end)
+}
+
+func (b *Client) FundingRateHistoryWithContext(ctx context.Context, symbol string, limit int, start, end int64) ([]*FundingData, error) {
 	opts := FundingRateOpts{
 		Symbol: symbol,
 		Limit:  limit,
@@ -14,7 +21,7 @@ func (b *Client) FundingRateHistory(symbol string, limit int, start, end int64)
 	if opts.Limit == 0 || opts.Limit > 1000 {
 		opts.Limit = 1000
 	}
-	res, err := b.do(http.MethodGet, "fapi/v1/fundingRate", opts, false, false)
+	res, err := b.do(ctx, http.MethodGet, "fapi/v1/fundingRate", opts, false, false)
 	if err != nil {
 		return nil, err
 	}
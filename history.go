@@ -0,0 +1,326 @@
+package appolloxapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	maxHistoryLimit = 1000
+	// allOrders and userTrades refuse a startTime to endTime window longer than 7 days
+	historyMaxWindow = 7 * 24 * time.Hour
+)
+
+type Order struct {
+	Symbol        string          `json:"symbol"`
+	OrderID       int64           `json:"orderId"`
+	ClientOrderID string          `json:"clientOrderId"`
+	Side          string          `json:"side"`
+	PositionSide  string          `json:"positionSide"`
+	Type          string          `json:"type"`
+	OrigType      string          `json:"origType"`
+	Status        string          `json:"status"`
+	TimeInForce   string          `json:"timeInForce"`
+	Price         decimal.Decimal `json:"price"`
+	AvgPrice      decimal.Decimal `json:"avgPrice"`
+	OrigQty       decimal.Decimal `json:"origQty"`
+	ExecutedQty   decimal.Decimal `json:"executedQty"`
+	CumQuote      decimal.Decimal `json:"cumQuote"`
+	StopPrice     decimal.Decimal `json:"stopPrice"`
+	ActivatePrice decimal.Decimal `json:"activatePrice"`
+	PriceRate     decimal.Decimal `json:"priceRate"`
+	WorkingType   string          `json:"workingType"`
+	ReduceOnly    bool            `json:"reduceOnly"`
+	ClosePosition bool            `json:"closePosition"`
+	PriceProtect  bool            `json:"priceProtect"`
+	Time          int64           `json:"time"`
+	UpdateTime    int64           `json:"updateTime"`
+}
+
+type UserTrade struct {
+	Symbol          string          `json:"symbol"`
+	ID              int64           `json:"id"`
+	OrderID         int64           `json:"orderId"`
+	Side            string          `json:"side"`
+	PositionSide    string          `json:"positionSide"`
+	Price           decimal.Decimal `json:"price"`
+	Qty             decimal.Decimal `json:"qty"`
+	QuoteQty        decimal.Decimal `json:"quoteQty"`
+	RealizedPnl     decimal.Decimal `json:"realizedPnl"`
+	Commission      decimal.Decimal `json:"commission"`
+	CommissionAsset string          `json:"commissionAsset"`
+	Buyer           bool            `json:"buyer"`
+	Maker           bool            `json:"maker"`
+	Time            int64           `json:"time"`
+}
+
+// orderId returns the orders from that id on, nil returns the most recent ones
+type AllOrdersOpts struct {
+	Symbol    string `url:"symbol"`
+	OrderID   *int64 `url:"orderId,omitempty"`
+	StartTime int64  `url:"startTime,omitempty"`
+	EndTime   int64  `url:"endTime,omitempty"`
+	Limit     int    `url:"limit,omitempty"`
+}
+
+// fromId can not be sent with startTime or endTime, nil returns the most recent ones
+type UserTradesOpts struct {
+	Symbol    string `url:"symbol"`
+	FromID    *int64 `url:"fromId,omitempty"`
+	StartTime int64  `url:"startTime,omitempty"`
+	EndTime   int64  `url:"endTime,omitempty"`
+	Limit     int    `url:"limit,omitempty"`
+}
+
+// one page, see AllOrdersFromID and AllOrdersBetween to walk further
+func (b *Client) AllOrders(opts AllOrdersOpts) ([]Order, error) {
+	return b.AllOrdersWithContext(context.Background(), opts)
+}
+
+func (b *Client) AllOrdersWithContext(ctx context.Context, opts AllOrdersOpts) ([]Order, error) {
+	opts.Symbol = strings.ToUpper(opts.Symbol)
+	if opts.Limit == 0 || opts.Limit > maxHistoryLimit {
+		opts.Limit = maxHistoryLimit
+	}
+	res, err := b.do(ctx, http.MethodGet, "fapi/v1/allOrders", opts, true, false)
+	if err != nil {
+		return nil, err
+	}
+	orders := []Order{}
+	err = json.Unmarshal(res, &orders)
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// one page, see UserTradesFromID and UserTradesBetween to walk further
+func (b *Client) UserTrades(opts UserTradesOpts) ([]UserTrade, error) {
+	return b.UserTradesWithContext(context.Background(), opts)
+}
+
+func (b *Client) UserTradesWithContext(ctx context.Context, opts UserTradesOpts) ([]UserTrade, error) {
+	opts.Symbol = strings.ToUpper(opts.Symbol)
+	if opts.Limit == 0 || opts.Limit > maxHistoryLimit {
+		opts.Limit = maxHistoryLimit
+	}
+	res, err := b.do(ctx, http.MethodGet, "fapi/v1/userTrades", opts, true, false)
+	if err != nil {
+		return nil, err
+	}
+	trades := []UserTrade{}
+	err = json.Unmarshal(res, &trades)
+	if err != nil {
+		return nil, err
+	}
+	return trades, nil
+}
+
+// walks like AggTradeIterator
+type OrderIterator struct {
+	client  *Client
+	symbol  string
+	cursor  historyCursor
+	buf     []Order
+	current Order
+	err     error
+}
+
+type UserTradeIterator struct {
+	client  *Client
+	symbol  string
+	cursor  historyCursor
+	buf     []UserTrade
+	current UserTrade
+	err     error
+}
+
+// walk from the order id up to the latest order
+func (b *Client) AllOrdersFromID(symbol string, orderID int64) *OrderIterator {
+	return &OrderIterator{
+		client: b,
+		symbol: strings.ToUpper(symbol),
+		cursor: historyCursor{byID: true, nextID: orderID, end: -1},
+	}
+}
+
+// walk the orders created within [start, end] in ms, 7 days at a time until the first hit
+func (b *Client) AllOrdersBetween(symbol string, start, end int64) *OrderIterator {
+	return &OrderIterator{
+		client: b,
+		symbol: strings.ToUpper(symbol),
+		cursor: historyCursor{from: start, end: end},
+	}
+}
+
+func (it *OrderIterator) Next(ctx context.Context) bool {
+	for len(it.buf) == 0 {
+		if it.cursor.done || it.err != nil {
+			return false
+		}
+		it.err = it.fetch(ctx)
+	}
+	it.current = it.buf[0]
+	it.buf = it.buf[1:]
+	if it.cursor.passed(it.current.Time) {
+		it.buf = nil
+		return false
+	}
+	return true
+}
+
+func (it *OrderIterator) Order() Order {
+	return it.current
+}
+
+func (it *OrderIterator) Err() error {
+	return it.err
+}
+
+// walk from the trade id up to the latest trade
+func (b *Client) UserTradesFromID(symbol string, fromID int64) *UserTradeIterator {
+	return &UserTradeIterator{
+		client: b,
+		symbol: strings.ToUpper(symbol),
+		cursor: historyCursor{byID: true, nextID: fromID, end: -1},
+	}
+}
+
+// walk the trades within [start, end] in ms, 7 days at a time until the first hit
+func (b *Client) UserTradesBetween(symbol string, start, end int64) *UserTradeIterator {
+	return &UserTradeIterator{
+		client: b,
+		symbol: strings.ToUpper(symbol),
+		cursor: historyCursor{from: start, end: end},
+	}
+}
+
+func (it *UserTradeIterator) Next(ctx context.Context) bool {
+	for len(it.buf) == 0 {
+		if it.cursor.done || it.err != nil {
+			return false
+		}
+		it.err = it.fetch(ctx)
+	}
+	it.current = it.buf[0]
+	it.buf = it.buf[1:]
+	if it.cursor.passed(it.current.Time) {
+		it.buf = nil
+		return false
+	}
+	return true
+}
+
+func (it *UserTradeIterator) Trade() UserTrade {
+	return it.current
+}
+
+func (it *UserTradeIterator) Err() error {
+	return it.err
+}
+
+// internal funcs ------------------------------------------------
+
+// searches by time window until the first record, then follows the ids.
+// end is -1 when walking by id only.
+type historyCursor struct {
+	byID   bool
+	nextID int64
+	from   int64
+	end    int64
+	done   bool
+}
+
+// the time window of the next request, false when the range is used up
+func (c *historyCursor) window() (int64, int64, bool) {
+	if c.from > c.end {
+		c.done = true
+		return 0, 0, false
+	}
+	to := c.from + int64(historyMaxWindow/time.Millisecond) - 1
+	if to > c.end {
+		to = c.end
+	}
+	return c.from, to, true
+}
+
+// n records came back, lastID is the id of the last one
+func (c *historyCursor) advance(n int, lastID, windowEnd int64) {
+	switch {
+	case n != 0:
+		c.byID = true
+		c.nextID = lastID + 1
+	case c.byID:
+		c.done = true
+	default:
+		c.from = windowEnd + 1
+	}
+}
+
+func (c *historyCursor) passed(stamp int64) bool {
+	if c.end >= 0 && stamp > c.end {
+		c.done = true
+		return true
+	}
+	return false
+}
+
+func (it *OrderIterator) fetch(ctx context.Context) error {
+	opts := AllOrdersOpts{
+		Symbol: it.symbol,
+	}
+	var to int64
+	if it.cursor.byID {
+		id := it.cursor.nextID
+		opts.OrderID = &id
+	} else {
+		var ok bool
+		if opts.StartTime, to, ok = it.cursor.window(); !ok {
+			return nil
+		}
+		opts.EndTime = to
+	}
+	orders, err := it.client.AllOrdersWithContext(ctx, opts)
+	if err != nil {
+		return err
+	}
+	var lastID int64
+	if len(orders) != 0 {
+		lastID = orders[len(orders)-1].OrderID
+	}
+	it.cursor.advance(len(orders), lastID, to)
+	it.buf = orders
+	return nil
+}
+
+func (it *UserTradeIterator) fetch(ctx context.Context) error {
+	opts := UserTradesOpts{
+		Symbol: it.symbol,
+	}
+	var to int64
+	if it.cursor.byID {
+		id := it.cursor.nextID
+		opts.FromID = &id
+	} else {
+		var ok bool
+		if opts.StartTime, to, ok = it.cursor.window(); !ok {
+			return nil
+		}
+		opts.EndTime = to
+	}
+	trades, err := it.client.UserTradesWithContext(ctx, opts)
+	if err != nil {
+		return err
+	}
+	var lastID int64
+	if len(trades) != 0 {
+		lastID = trades[len(trades)-1].ID
+	}
+	it.cursor.advance(len(trades), lastID, to)
+	it.buf = trades
+	return nil
+}
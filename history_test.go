@@ -0,0 +1,44 @@
+package appolloxapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHistoryFromIDZero(t *testing.T) {
+	ids := make(chan string, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if _, ok := query["orderId"]; ok {
+			ids <- "orderId=" + query.Get("orderId")
+		}
+		if _, ok := query["fromId"]; ok {
+			ids <- "fromId=" + query.Get("fromId")
+		}
+		w.Write([]byte("[]"))
+	}))
+	defer srv.Close()
+	client := New("key", "secret", "", WithBaseURL(srv.URL))
+	ctx := context.Background()
+
+	orders := client.AllOrdersFromID("btcusdt", 0)
+	if orders.Next(ctx) || orders.Err() != nil {
+		t.Fatalf("want an empty walk, got err %v", orders.Err())
+	}
+	trades := client.UserTradesFromID("btcusdt", 0)
+	if trades.Next(ctx) || trades.Err() != nil {
+		t.Fatalf("want an empty walk, got err %v", trades.Err())
+	}
+	for _, want := range []string{"orderId=0", "fromId=0"} {
+		select {
+		case got := <-ids:
+			if got != want {
+				t.Fatalf("sent %s, want %s", got, want)
+			}
+		default:
+			t.Fatalf("%s not sent", want)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package appolloxapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type KlineInterval string
+
+const (
+	Interval1m  KlineInterval = "1m"
+	Interval3m  KlineInterval = "3m"
+	Interval5m  KlineInterval = "5m"
+	Interval15m KlineInterval = "15m"
+	Interval30m KlineInterval = "30m"
+	Interval1h  KlineInterval = "1h"
+	Interval2h  KlineInterval = "2h"
+	Interval4h  KlineInterval = "4h"
+	Interval6h  KlineInterval = "6h"
+	Interval8h  KlineInterval = "8h"
+	Interval12h KlineInterval = "12h"
+	Interval1d  KlineInterval = "1d"
+	Interval3d  KlineInterval = "3d"
+	Interval1w  KlineInterval = "1w"
+	Interval1M  KlineInterval = "1M"
+)
+
+// which klines endpoint to read
+type KlineKind string
+
+const (
+	KlineTrade      KlineKind = "fapi/v1/klines"
+	KlineContinuous KlineKind = "fapi/v1/continuousKlines"
+	KlineIndexPrice KlineKind = "fapi/v1/indexPriceKlines"
+	KlineMarkPrice  KlineKind = "fapi/v1/markPriceKlines"
+)
+
+const (
+	ContractTypePerpetual      = "PERPETUAL"
+	ContractTypeCurrentQuarter = "CURRENT_QUARTER"
+	ContractTypeNextQuarter    = "NEXT_QUARTER"
+	maxKlinesLimit             = 1500
+	defaultKlinesLimit         = 500
+)
+
+// volumes are zero for index and mark price klines
+type Kline struct {
+	OpenTime                 int64
+	Open                     decimal.Decimal
+	High                     decimal.Decimal
+	Low                      decimal.Decimal
+	Close                    decimal.Decimal
+	Volume                   decimal.Decimal
+	CloseTime                int64
+	QuoteAssetVolume         decimal.Decimal
+	NumberOfTrades           int64
+	TakerBuyBaseAssetVolume  decimal.Decimal
+	TakerBuyQuoteAssetVolume decimal.Decimal
+}
+
+type KlinesOpts struct {
+	Symbol       string `url:"symbol,omitempty"`
+	Pair         string `url:"pair,omitempty"`
+	ContractType string `url:"contractType,omitempty"`
+	Interval     string `url:"interval"`
+	StartTime    int64  `url:"startTime,omitempty"`
+	EndTime      int64  `url:"endTime,omitempty"`
+	Limit        int    `url:"limit,omitempty"`
+}
+
+// default limit is 500, more than 1500 is cut to 1500
+func (b *Client) Klines(symbol string, interval KlineInterval, limit int, start, end int64) ([]Kline, error) {
+	return b.KlinesWithContext(context.Background(), symbol, interval, limit, start, end)
+}
+
+func (b *Client) KlinesWithContext(ctx context.Context, symbol string, interval KlineInterval, limit int, start, end int64) ([]Kline, error) {
+	opts := KlinesOpts{
+		Symbol: strings.ToUpper(symbol),
+	}
+	return b.klines(ctx, KlineTrade, opts, interval, limit, start, end)
+}
+
+// contractType is PERPETUAL, CURRENT_QUARTER or NEXT_QUARTER
+func (b *Client) ContinuousKlines(pair, contractType string, interval KlineInterval, limit int, start, end int64) ([]Kline, error) {
+	return b.ContinuousKlinesWithContext(context.Background(), pair, contractType, interval, limit, start, end)
+}
+
+func (b *Client) ContinuousKlinesWithContext(ctx context.Context, pair, contractType string, interval KlineInterval, limit int, start, end int64) ([]Kline, error) {
+	opts := KlinesOpts{
+		Pair:         strings.ToUpper(pair),
+		ContractType: strings.ToUpper(contractType),
+	}
+	return b.klines(ctx, KlineContinuous, opts, interval, limit, start, end)
+}
+
+func (b *Client) IndexPriceKlines(pair string, interval KlineInterval, limit int, start, end int64) ([]Kline, error) {
+	return b.IndexPriceKlinesWithContext(context.Background(), pair, interval, limit, start, end)
+}
+
+func (b *Client) IndexPriceKlinesWithContext(ctx context.Context, pair string, interval KlineInterval, limit int, start, end int64) ([]Kline, error) {
+	opts := KlinesOpts{
+		Pair: strings.ToUpper(pair),
+	}
+	return b.klines(ctx, KlineIndexPrice, opts, interval, limit, start, end)
+}
+
+func (b *Client) MarkPriceKlines(symbol string, interval KlineInterval, limit int, start, end int64) ([]Kline, error) {
+	return b.MarkPriceKlinesWithContext(context.Background(), symbol, interval, limit, start, end)
+}
+
+func (b *Client) MarkPriceKlinesWithContext(ctx context.Context, symbol string, interval KlineInterval, limit int, start, end int64) ([]Kline, error) {
+	opts := KlinesOpts{
+		Symbol: strings.ToUpper(symbol),
+	}
+	return b.klines(ctx, KlineMarkPrice, opts, interval, limit, start, end)
+}
+
+// every kline opening within [start, end] in ms, fetched in pages of 1500.
+// opts picks the series like the single page calls do, its time range and limit are ignored.
+func (b *Client) KlinesRange(kind KlineKind, opts KlinesOpts, start, end int64) ([]Kline, error) {
+	return b.KlinesRangeWithContext(context.Background(), kind, opts, start, end)
+}
+
+func (b *Client) KlinesRangeWithContext(ctx context.Context, kind KlineKind, opts KlinesOpts, start, end int64) ([]Kline, error) {
+	if end < start {
+		return nil, errors.New("end should not be before start")
+	}
+	interval := KlineInterval(opts.Interval)
+	step, err := interval.Duration()
+	if err != nil {
+		return nil, err
+	}
+	opts.Symbol = strings.ToUpper(opts.Symbol)
+	opts.Pair = strings.ToUpper(opts.Pair)
+	opts.ContractType = strings.ToUpper(opts.ContractType)
+	if now := time.Now().UnixNano() / int64(time.Millisecond); end > now {
+		end = now
+	}
+	result := []Kline{}
+	from := start
+	for from <= end {
+		// a page can not hold more than 1500 candles, so split the window up front
+		to := from + int64(maxKlinesLimit)*int64(step/time.Millisecond) - 1
+		if to > end || interval == Interval1M {
+			to = end
+		}
+		page, err := b.klines(ctx, kind, opts, interval, maxKlinesLimit, from, to)
+		if err != nil {
+			return nil, err
+		}
+		if len(page) == 0 {
+			from = to + 1
+			continue
+		}
+		for _, k := range page {
+			// pages overlap on the boundary candle
+			if len(result) != 0 && k.OpenTime <= result[len(result)-1].OpenTime {
+				continue
+			}
+			if k.OpenTime > end {
+				break
+			}
+			result = append(result, k)
+		}
+		from = page[len(page)-1].OpenTime + 1
+	}
+	return result, nil
+}
+
+// fixed length of the interval, 1M is counted as 31 days
+func (i KlineInterval) Duration() (time.Duration, error) {
+	day := 24 * time.Hour
+	switch i {
+	case Interval1m:
+		return time.Minute, nil
+	case Interval3m:
+		return 3 * time.Minute, nil
+	case Interval5m:
+		return 5 * time.Minute, nil
+	case Interval15m:
+		return 15 * time.Minute, nil
+	case Interval30m:
+		return 30 * time.Minute, nil
+	case Interval1h:
+		return time.Hour, nil
+	case Interval2h:
+		return 2 * time.Hour, nil
+	case Interval4h:
+		return 4 * time.Hour, nil
+	case Interval6h:
+		return 6 * time.Hour, nil
+	case Interval8h:
+		return 8 * time.Hour, nil
+	case Interval12h:
+		return 12 * time.Hour, nil
+	case Interval1d:
+		return day, nil
+	case Interval3d:
+		return 3 * day, nil
+	case Interval1w:
+		return 7 * day, nil
+	case Interval1M:
+		return 31 * day, nil
+	}
+	return 0, fmt.Errorf("unknown kline interval %q", string(i))
+}
+
+// [openTime, open, high, low, close, volume, closeTime, quoteVolume, trades, takerBase, takerQuote, ignore]
+func (k *Kline) UnmarshalJSON(data []byte) error {
+	var raw []interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw) < 11 {
+		return fmt.Errorf("kline with %d fields", len(raw))
+	}
+	var err error
+	ints := []struct {
+		idx int
+		dst *int64
+	}{{0, &k.OpenTime}, {6, &k.CloseTime}, {8, &k.NumberOfTrades}}
+	for _, field := range ints {
+		value, ok := raw[field.idx].(float64)
+		if !ok {
+			return fmt.Errorf("kline field %d is not a number", field.idx)
+		}
+		*field.dst = int64(value)
+	}
+	decimals := []struct {
+		idx int
+		dst *decimal.Decimal
+	}{
+		{1, &k.Open}, {2, &k.High}, {3, &k.Low}, {4, &k.Close}, {5, &k.Volume},
+		{7, &k.QuoteAssetVolume}, {9, &k.TakerBuyBaseAssetVolume}, {10, &k.TakerBuyQuoteAssetVolume},
+	}
+	for _, field := range decimals {
+		value, ok := raw[field.idx].(string)
+		if !ok {
+			return fmt.Errorf("kline field %d is not a string", field.idx)
+		}
+		if *field.dst, err = decimal.NewFromString(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// internal funcs ------------------------------------------------
+
+func (b *Client) klines(ctx context.Context, kind KlineKind, opts KlinesOpts, interval KlineInterval, limit int, start, end int64) ([]Kline, error) {
+	opts.Interval = string(interval)
+	opts.Limit = limit
+	if opts.Limit <= 0 {
+		opts.Limit = defaultKlinesLimit
+	}
+	if opts.Limit > maxKlinesLimit {
+		opts.Limit = maxKlinesLimit
+	}
+	opts.StartTime = start
+	opts.EndTime = end
+	res, err := b.do(ctx, http.MethodGet, string(kind), opts, false, false)
+	if err != nil {
+		return nil, err
+	}
+	klines := []Kline{}
+	err = json.Unmarshal(res, &klines)
+	if err != nil {
+		return nil, err
+	}
+	return klines, nil
+}
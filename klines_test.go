@@ -0,0 +1,33 @@
+package appolloxapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestKlinesLimit(t *testing.T) {
+	limits := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limits <- r.URL.Query().Get("limit")
+		w.Write([]byte("[]"))
+	}))
+	defer srv.Close()
+	client := New("key", "secret", "", WithBaseURL(srv.URL))
+	cases := []struct {
+		limit int
+		want  string
+	}{
+		{0, "500"},
+		{1000, "1000"},
+		{2000, "1500"},
+	}
+	for _, tc := range cases {
+		if _, err := client.Klines("BTCUSDT", Interval1m, tc.limit, 0, 0); err != nil {
+			t.Fatal(err)
+		}
+		if got := <-limits; got != tc.want {
+			t.Fatalf("limit %d sent as %s, want %s", tc.limit, got, tc.want)
+		}
+	}
+}
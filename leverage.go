@@ -1,15 +1,21 @@
 package appolloxapi
 
 import (
+	"context"
 	"net/http"
+	"strings"
 )
 
 func (b *Client) ChangeInitialLeverage(symbol string, leverage int) (*ChangeLeverageResponse, error) {
+	return b.ChangeInitialLeverageWithContext(context.Background(), symbol, leverage)
+}
+
+func (b *Client) ChangeInitialLeverageWithContext(ctx context.Context, symbol string, leverage int) (*ChangeLeverageResponse, error) {
 	opts := ChnageLeverageOpts{
-		Symbol:   symbol,
+		Symbol:   strings.ToUpper(symbol),
 		Leverage: leverage,
 	}
-	res, err := b.do(http.MethodPost, "fapi/v1/leverage", opts, true, false)
+	res, err := b.do(ctx, http.MethodPost, "fapi/v1/leverage", opts, true, false)
 	if err != nil {
 		return nil, err
 	}
@@ -23,8 +29,8 @@ func (b *Client) ChangeInitialLeverage(symbol string, leverage int) (*ChangeLeve
 }
 
 type ChnageLeverageOpts struct {
-	Symbol   string `json:"json"`
-	Leverage int    `json:"leverage"`
+	Symbol   string `url:"symbol"`
+	Leverage int    `url:"leverage"`
 }
 
 type ChangeLeverageResponse struct {
@@ -34,7 +40,11 @@ type ChangeLeverageResponse struct {
 }
 
 func (b *Client) NotionalandLeverage() (*[]NotionalandLeverage, error) {
-	res, err := b.do(http.MethodGet, "fapi/v1/leverageBracket", nil, true, false)
+	return b.NotionalandLeverageWithContext(context.Background())
+}
+
+func (b *Client) NotionalandLeverageWithContext(ctx context.Context) (*[]NotionalandLeverage, error) {
+	res, err := b.do(ctx, http.MethodGet, "fapi/v1/leverageBracket", nil, true, false)
 	if err != nil {
 		return nil, err
 	}
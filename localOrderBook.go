@@ -14,6 +14,7 @@ import (
 )
 
 type OrderBookBranch struct {
+	client        *Client
 	bids          bookBranch
 	asks          bookBranch
 	lastUpdatedId lastUpdateIdbranch
@@ -26,6 +27,8 @@ type OrderBookBranch struct {
 	toLevel       int
 	reCh          chan error
 	lastRefresh   lastRefreshBranch
+	// last agg trade id put into the trade impact, only touched by maintainOrderBook
+	lastAggID int64
 }
 
 type lastUpdateIdbranch struct {
@@ -89,8 +92,14 @@ func (o *OrderBookBranch) IfCanRefresh() bool {
 
 // logurs as log system
 func (o *OrderBookBranch) GetOrderBookSnapShot(symbol string) error {
-	client := New("", "", "")
-	res, err := client.Depth(symbol, 1000)
+	return o.getOrderBookSnapShot(context.Background(), symbol)
+}
+
+func (o *OrderBookBranch) getOrderBookSnapShot(ctx context.Context, symbol string) error {
+	if o.client == nil {
+		return errors.New("orderbook branch without client, use Client.LocalOrderBook")
+	}
+	res, err := o.client.DepthWithContext(ctx, symbol, 1000)
 	if err != nil {
 		return err
 	}
@@ -592,8 +601,17 @@ func reStartMainSeesionErrHub(err string) bool {
 	return true
 }
 
+// public market data only, same as New("", "", "").LocalOrderBook
 func LocalOrderBook(symbol string, logger *log.Logger, streamTrade bool) *OrderBookBranch {
+	return New("", "", "").LocalOrderBook(symbol, logger, streamTrade)
+}
+
+// the rest walk before the stream is read, well under the 10 sec without updates
+const tradeBackfillTimeout = 3 * time.Second
+
+func (c *Client) LocalOrderBook(symbol string, logger *log.Logger, streamTrade bool) *OrderBookBranch {
 	var o OrderBookBranch
+	o.client = c
 	o.SetLookBackSec(5)
 	o.SetImpactCumRange(5)
 	ctx, cancel := context.WithCancel(context.Background())
@@ -610,7 +628,7 @@ func LocalOrderBook(symbol string, logger *log.Logger, streamTrade bool) *OrderB
 			case <-ctx.Done():
 				return
 			default:
-				if err := apxSocket(ctx, symbol, "@depth@100ms", logger, &bookticker, &orderBookErr); err == nil {
+				if err := c.apxSocket(ctx, symbol, "@depth@100ms", logger, &bookticker, &orderBookErr); err == nil {
 					return
 				} else {
 					if reStartMainSeesionErrHub(err.Error()) {
@@ -632,7 +650,7 @@ func LocalOrderBook(symbol string, logger *log.Logger, streamTrade bool) *OrderB
 				case <-ctx.Done():
 					return
 				default:
-					if err := apxSocket(ctx, symbol, tradeChannel, logger, &bookticker, &tradeErr); err == nil {
+					if err := c.apxSocket(ctx, symbol, tradeChannel, logger, &bookticker, &tradeErr); err == nil {
 						return
 					} else {
 						if reStartMainSeesionErrHub(err.Error()) {
@@ -651,7 +669,7 @@ func LocalOrderBook(symbol string, logger *log.Logger, streamTrade bool) *OrderB
 			case <-ctx.Done():
 				return
 			default:
-				err := o.maintainOrderBook(ctx, symbol, streamTrade, &bookticker, &errCh, &orderBookErr, &tradeErr)
+				err := o.maintainOrderBook(ctx, symbol, streamTrade, logger, &bookticker, &errCh, &orderBookErr, &tradeErr)
 				if err == nil {
 					return
 				}
@@ -667,6 +685,7 @@ func (o *OrderBookBranch) maintainOrderBook(
 	ctx context.Context,
 	symbol string,
 	streamTrade bool,
+	logger *log.Logger,
 	bookticker *chan map[string]interface{},
 	errCh *chan error,
 	orderBookErr *chan error,
@@ -676,12 +695,21 @@ func (o *OrderBookBranch) maintainOrderBook(
 	var linked bool = false
 	o.snapShoted = false
 	o.UpdateLastUpdateId(decimal.Zero)
+	if streamTrade {
+		// best effort, the stream keeps filling the window anyway
+		backfillCtx, cancel := context.WithTimeout(ctx, tradeBackfillTimeout)
+		if err := o.backfillTradeImpact(backfillCtx, symbol); err != nil {
+			logger.Warningf("Backfill %s trade impact with err: %s\n", symbol, err.Error())
+		}
+		cancel()
+	}
+	// after the backfill, it does not count against the no update timeout
 	lastUpdate := time.Now()
 	snapshotErr := make(chan error, 1)
 	go func() {
 		// avoid latancy issue
 		time.Sleep(time.Second * 3)
-		if err := o.GetOrderBookSnapShot(symbol); err != nil {
+		if err := o.getOrderBookSnapShot(ctx, symbol); err != nil {
 			snapshotErr <- err
 		}
 	}()
@@ -732,12 +760,29 @@ func (o *OrderBookBranch) maintainOrderBook(
 				// update last update
 				lastUpdate = time.Now()
 			default:
-				st := formatingTimeStamp(message["T"].(float64))
-				price, _ := decimal.NewFromString(message["p"].(string))
-				size, _ := decimal.NewFromString(message["q"].(string))
+				stamp, okT := message["T"].(float64)
+				rawPrice, okP := message["p"].(string)
+				rawSize, okQ := message["q"].(string)
 				// is the buyer the mm
+				buyerIsMM, okM := message["m"].(bool)
+				if !okT || !okP || !okQ || !okM {
+					// skip a malformed trade
+					continue
+				}
+				price, errP := decimal.NewFromString(rawPrice)
+				size, errQ := decimal.NewFromString(rawSize)
+				if errP != nil || errQ != nil {
+					continue
+				}
+				if id, ok := message["a"].(float64); ok {
+					// already taken by the backfill
+					if int64(id) <= o.lastAggID {
+						continue
+					}
+					o.lastAggID = int64(id)
+				}
+				st := formatingTimeStamp(stamp)
 				var side string
-				buyerIsMM := message["m"].(bool)
 				if buyerIsMM {
 					side = "sell"
 				} else {
@@ -778,6 +823,31 @@ func (o *OrderBookBranch) locateTradeImpact(side string, price, size decimal.Dec
 	}
 }
 
+// refill the look back window with the agg trades missed while the stream was down
+func (o *OrderBookBranch) backfillTradeImpact(ctx context.Context, symbol string) error {
+	if o.client == nil {
+		return errors.New("orderbook branch without client, use Client.LocalOrderBook")
+	}
+	now := time.Now()
+	start := now.Add(-o.LookBack).UnixNano() / int64(time.Millisecond)
+	end := now.UnixNano() / int64(time.Millisecond)
+	it := o.client.AggTradesBetween(symbol, start, end)
+	for it.Next(ctx) {
+		trade := it.Trade()
+		if trade.AggID <= o.lastAggID {
+			continue
+		}
+		side := "buy"
+		if trade.IsBuyerMaker {
+			side = "sell"
+		}
+		o.locateTradeImpact(side, trade.Price, trade.Qty, time.Unix(0, trade.Time*int64(time.Millisecond)))
+		o.lastAggID = trade.AggID
+	}
+	o.renewTradeImpact()
+	return it.Err()
+}
+
 func (o *OrderBookBranch) renewTradeImpact() {
 	var wg sync.WaitGroup
 	wg.Add(2)
@@ -862,14 +932,15 @@ func decodingMap(message []byte, logger *log.Logger) (res map[string]interface{}
 	return res, nil
 }
 
-func apxSocket(ctx context.Context, symbol, channel string, logger *log.Logger, mainCh *chan map[string]interface{}, reCh *chan error) error {
+func (c *Client) apxSocket(ctx context.Context, symbol, channel string, logger *log.Logger, mainCh *chan map[string]interface{}, reCh *chan error) error {
 	var w wS
 	var duration time.Duration = 300
 	w.Channel = channel
 	w.Logger = logger
 	w.OnErr = false
 	var buffer bytes.Buffer
-	buffer.WriteString("wss://fstream.apollox.finance/stream?streams=")
+	buffer.WriteString(c.wsBaseURL)
+	buffer.WriteString("/stream?streams=")
 	buffer.WriteString(strings.ToLower(symbol))
 	buffer.WriteString(w.Channel)
 	url := buffer.String()
@@ -964,9 +1035,9 @@ func (w *wS) handleapxSocketData(res map[string]interface{}, mainCh *chan map[st
 		w.LastUpdatedId = tailID
 		*mainCh <- data
 	case "trade":
-		*mainCh <- res
+		*mainCh <- data
 	case "aggTrade":
-		*mainCh <- res
+		*mainCh <- data
 	}
 	return nil
 }
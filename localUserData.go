@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -14,12 +15,32 @@ import (
 	"github.com/gorilla/websocket"
 )
 
+// OnEvent, OnMarginCall and OnConnState handlers run in registration order on the goroutine
+// making the change, a slow one holds up the stream. OnTrade, OnOrder and the other
+// subscriptions run on their own goroutine, see SubscribeOpts.
 type UserDataBranch struct {
 	account            AccountBranch
+	client             *Client
 	cancel             *context.CancelFunc
 	httpUpdateInterval int
 	errs               chan error
-	trades             chan TradeData
+	trades             legacyTrades
+	subs               subscriptionHub
+	orders             orderBranch
+	marginCalls        marginCallBranch
+	events             eventHandlers
+	stream             streamState
+}
+
+type legacyTrades struct {
+	sync.Mutex
+	ch  <-chan TradeData
+	sub *Subscription
+}
+
+type eventHandlers struct {
+	sync.RWMutex
+	list []func(UserDataEvent)
 }
 
 type AccountBranch struct {
@@ -38,8 +59,9 @@ type TradeData struct {
 	TimeStamp time.Time
 }
 
+// deletes the listen key too
 func (u *UserDataBranch) Close() {
-	(*u.cancel)()
+	u.closeStream()
 }
 
 // default is 60 sec
@@ -53,21 +75,43 @@ func (u *UserDataBranch) AccountData() (*AccountResponse, error) {
 	return u.account.Data, u.readerrs()
 }
 
+// side is BOTH in one-way mode, LONG or SHORT in hedge mode
+func (u *UserDataBranch) Position(symbol, positionSide string) (PositionsInAccount, bool) {
+	u.account.RLock()
+	defer u.account.RUnlock()
+	if u.account.Data == nil {
+		return PositionsInAccount{}, false
+	}
+	return u.account.Data.Position(symbol, positionSide)
+}
+
+// every decoded event after the local account and orders took it in
+func (u *UserDataBranch) OnEvent(handler func(UserDataEvent)) {
+	u.events.Lock()
+	defer u.events.Unlock()
+	u.events.list = append(u.events.list, handler)
+}
+
+// Deprecated: keeps the last 100 trades since the first call only, use SubscribeTrades to pick a policy.
 func (u *UserDataBranch) ReadTrade() (TradeData, error) {
-	if data, ok := <-u.trades; ok {
+	if data, ok := <-u.tradeChannel(); ok {
 		return data, nil
 	}
 	return TradeData{}, errors.New("trade channel already closed.")
 }
 
-// default errs cap 5, trades cap 100
+// default errs cap 5, trades cap 100, margin calls cap 10.
+// returns once ready or after 5 sec, see WaitReady.
 func (c *Client) LocalUserData(logger *log.Logger) *UserDataBranch {
 	var u UserDataBranch
+	u.client = c
 	ctx, cancel := context.WithCancel(context.Background())
 	u.cancel = &cancel
 	u.httpUpdateInterval = 60
 	u.initialChannels()
-	userData := make(chan map[string]interface{}, 100)
+	u.initialStream()
+	u.orders.byID = make(map[int64]*OrderState)
+	userData := make(chan []byte, 100)
 	// stream user data
 	go func() {
 		for {
@@ -75,44 +119,41 @@ func (c *Client) LocalUserData(logger *log.Logger) *UserDataBranch {
 			case <-ctx.Done():
 				return
 			default:
-				res, err := c.GetListenKey() // delete listen key
+				u.streamDisconnected()
+				res, err := c.GetListenKeyWithContext(ctx)
 				if err != nil {
 					log.Println("retry listen key for user data stream in 5 sec..")
 					time.Sleep(time.Second * 5)
 					continue
 				}
-				if err := c.userData(ctx, res.ListenKey, logger, &userData); err == nil {
+				u.setListenKey(res.ListenKey)
+				err = c.userData(ctx, res.ListenKey, logger, &userData, u.stream.reconnect, u.streamConnected)
+				if err == nil {
 					return
 				}
-				time.Sleep(time.Second)
-			}
-		}
-	}()
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			default:
-				if err := u.maintainUserData(ctx, c, &userData); err == nil {
-					return
-				} else {
-					logger.Warningf("Refreshing apx local user data with err: %s.\n", err.Error())
+				if errors.Is(err, errListenKeyExpired) {
+					// a fresh key right away
+					continue
 				}
+				time.Sleep(time.Second)
 			}
 		}
 	}()
+	go u.keepUserData(ctx, c, logger, &userData)
 	// wait for connecting
-	time.Sleep(time.Second * 5)
+	select {
+	case <-u.stream.ready:
+	case <-time.After(time.Second * 5):
+	}
 	return &u
 }
 
 // internal funcs ------------------------------------------------
 
-func (u *UserDataBranch) getAccountSnapShot(client *Client) error {
+func (u *UserDataBranch) getAccountSnapShot(ctx context.Context, client *Client) error {
 	u.account.Lock()
 	defer u.account.Unlock()
-	res, err := client.Account()
+	res, err := client.AccountWithContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -120,155 +161,152 @@ func (u *UserDataBranch) getAccountSnapShot(client *Client) error {
 	return nil
 }
 
+// run maintainUserData again after each failure until ctx is done
+func (u *UserDataBranch) keepUserData(ctx context.Context, client *Client, logger *log.Logger, userData *chan []byte) {
+	// every way out closes the channels of the consumers
+	defer u.closeChannels()
+	attempt := 0
+	for {
+		started := time.Now()
+		err := u.maintainUserData(ctx, client, userData)
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+		logger.Warningf("Refreshing apx local user data with err: %s.\n", err.Error())
+		if u.ConnState() == ConnLive {
+			u.setConnState(ConnResyncing)
+		}
+		// a run that held up for a while starts the backoff over
+		if time.Since(started) > userDataRetry.MaxDelay {
+			attempt = 0
+		}
+		if userDataRetry.wait(ctx, attempt) != nil {
+			return
+		}
+		attempt++
+	}
+}
+
 func (u *UserDataBranch) maintainUserData(
 	ctx context.Context,
 	client *Client,
-	userData *chan map[string]interface{},
+	userData *chan []byte,
 ) error {
 	// get the first snapshot to initial data struct
-	if err := u.getAccountSnapShot(client); err != nil {
+	if err := u.getAccountSnapShot(ctx, client); err != nil {
+		return err
+	}
+	if err := u.getOpenOrdersSnapShot(ctx, client); err != nil {
 		return err
 	}
-	// update snapshot with steady interval
+	u.snapshotTaken()
+	// update snapshot with steady interval, stopped whenever this returns
+	snapCtx, stopSnap := context.WithCancel(ctx)
+	snapDone := make(chan struct{})
+	defer func() {
+		stopSnap()
+		<-snapDone
+	}()
 	go func() {
+		defer close(snapDone)
 		snap := time.NewTicker(time.Second * time.Duration(u.httpUpdateInterval))
 		defer snap.Stop()
 		for {
 			select {
-			case <-ctx.Done():
+			case <-snapCtx.Done():
 				return
 			case <-snap.C:
-				if err := u.getAccountSnapShot(client); err != nil {
+				u.pruneClosedOrders(time.Now())
+				if err := u.getAccountSnapShot(snapCtx, client); err != nil && snapCtx.Err() == nil {
 					u.insertErr(err)
 				}
-			default:
-				time.Sleep(time.Second)
 			}
 		}
 	}()
 	for {
 		select {
 		case <-ctx.Done():
-			close(u.errs)
-			close(u.trades)
 			return nil
-		default:
-			message := <-(*userData)
-			event, ok := message["e"].(string)
-			if !ok {
+		case raw := <-(*userData):
+			event, err := DecodeUserDataEvent(raw)
+			if err != nil {
+				// new event types are skipped quietly
+				if !errors.Is(err, ErrUnknownEvent) {
+					u.insertErr(err)
+				}
 				continue
 			}
-			switch event {
-			case "ACCOUNT_UPDATE":
-				if data, ok := message["a"].(map[string]interface{}); !ok {
-					continue
-				} else {
-					u.handleAccountUpdate(&data)
-				}
-			case "ORDER_TRADE_UPDATE":
-				if data, ok := message["o"].(map[string]interface{}); !ok {
-					continue
-				} else {
-					if event, ok := data["x"].(string); ok {
-						switch event {
-						case "TRADE":
-							u.handleTrade(&data)
-						default:
-							// order update in the future
-						}
-					}
-				}
-			default:
-				// pass
+			if time.Now().After(event.EventTime().Add(time.Minute * 60)) {
+				continue
 			}
+			u.handleEvent(ctx, event)
+		case <-u.stream.resync:
+			// events were missed while the stream was down
+			if err := u.getAccountSnapShot(ctx, client); err != nil {
+				return err
+			}
+			if err := u.getOpenOrdersSnapShot(ctx, client); err != nil {
+				return err
+			}
+			u.snapshotTaken()
 		}
 	}
 }
 
-// default fee asset is USDT
-func (u *UserDataBranch) handleTrade(res *map[string]interface{}) {
-	data := TradeData{}
-	if symbol, ok := (*res)["s"].(string); ok {
-		data.Symbol = symbol
-	} else {
-		return
-	}
-	if side, ok := (*res)["S"].(string); ok {
-		data.Side = strings.ToLower(side)
-	} else {
-		return
-	}
-	if qty, ok := (*res)["l"].(string); ok {
-		data.Qty, _ = decimal.NewFromString(qty)
-	} else {
-		return
-	}
-	if price, ok := (*res)["L"].(string); ok {
-		data.Price, _ = decimal.NewFromString(price)
-	} else {
-		return
-	}
-	if oid, ok := (*res)["i"].(float64); ok {
-		data.Oid = decimal.NewFromFloat(oid).String()
-	} else {
-		return
-	}
-	if execType, ok := (*res)["m"].(bool); ok {
-		data.IsMaker = execType
+func (u *UserDataBranch) handleEvent(ctx context.Context, event UserDataEvent) {
+	switch e := event.(type) {
+	case *MarginCallEvent:
+		u.handleMarginCall(ctx, e)
+	case *AccountUpdateEvent:
+		u.handleAccountUpdate(&e.Update)
+	case *OrderTradeUpdateEvent:
+		u.handleOrderUpdate(&e.Order)
+		if e.Order.ExecType == ExecTypeTrade {
+			u.handleTrade(&e.Order)
+		}
+	case *AccountConfigUpdateEvent:
+		u.handleAccountConfigUpdate(e)
+	case *ListenKeyExpiredEvent:
+		u.requestReconnect()
 	}
-	if fee, ok := (*res)["n"].(string); ok {
-		data.Fee, _ = decimal.NewFromString(fee)
+	u.events.RLock()
+	defer u.events.RUnlock()
+	for _, handler := range u.events.list {
+		handler(event)
 	}
-	if st, ok := (*res)["T"].(float64); ok {
-		stamp := formatingTimeStamp(st)
-		data.TimeStamp = stamp
+}
+
+// default fee asset is USDT
+func (u *UserDataBranch) handleTrade(order *OrderUpdate) {
+	data := TradeData{
+		Symbol:    order.Symbol,
+		Side:      strings.ToLower(order.Side),
+		Oid:       strconv.FormatInt(order.OrderID, 10),
+		IsMaker:   order.IsMaker,
+		Price:     order.LastFilledPrice,
+		Qty:       order.LastFilledQty,
+		Fee:       order.Commission,
+		TimeStamp: formatingTimeStamp(float64(order.TradeTime)),
 	}
 	u.insertTrade(&data)
 }
 
-func (u *UserDataBranch) handleAccountUpdate(res *map[string]interface{}) {
-	if balances, ok := (*res)["B"].([]interface{}); ok {
-		for _, item := range balances {
-			data := item.(map[string]interface{})
-			var asset, walletBalance, crossWalletBalance string
-			if a, ok := data["a"].(string); ok {
-				asset = a
-			}
-			if wb, ok := data["wb"].(string); ok {
-				walletBalance = wb
-			}
-			if cw, ok := data["cw"].(string); ok {
-				crossWalletBalance = cw
-			}
-			u.updateBalanceData(asset, walletBalance, crossWalletBalance)
-		}
+func (u *UserDataBranch) handleAccountUpdate(update *AccountUpdate) {
+	for _, balance := range update.Balances {
+		u.updateBalanceData(balance.Asset, balance.WalletBalance.String(), balance.CrossWalletBalance.String())
+		u.subs.publish(TopicBalances, balance)
 	}
-	if positions, ok := (*res)["P"].([]interface{}); ok {
-		for _, item := range positions {
-			data := item.(map[string]interface{})
-			var symbol, amount, entryPrice, unPnl, marginType, positionSide string
-			if s, ok := data["s"].(string); ok {
-				symbol = s
-			}
-			if pa, ok := data["pa"].(string); ok {
-				amount = pa
-			}
-			if ep, ok := data["ep"].(string); ok {
-				entryPrice = ep
-			}
-			if up, ok := data["up"].(string); ok {
-				unPnl = up
-			}
-			if mt, ok := data["mt"].(string); ok {
-				marginType = mt
-			}
-			if ps, ok := data["ps"].(string); ok {
-				positionSide = ps
-			}
-			u.updatePositionData(symbol, amount, entryPrice, unPnl, marginType, positionSide)
-		}
+	for _, position := range update.Positions {
+		u.updatePositionData(
+			position.Symbol,
+			position.PositionAmt.String(),
+			position.EntryPrice.String(),
+			position.UnrealizedPnl.String(),
+			position.MarginType,
+			position.PositionSide,
+		)
+		u.subs.publish(TopicPositions, position)
 	}
-
 }
 
 func (u *UserDataBranch) updateBalanceData(asset, walletBalance, crossWalletBalance string) {
@@ -283,37 +321,52 @@ func (u *UserDataBranch) updateBalanceData(asset, walletBalance, crossWalletBala
 	}
 }
 
+// positions are matched by symbol and side, so hedge mode keeps both legs
 func (u *UserDataBranch) updatePositionData(symbol, amount, entryPrice, unPnl, marginType, positionSide string) {
 	u.account.Lock()
 	defer u.account.Unlock()
+	key := PositionKey{Symbol: symbol, PositionSide: positionSide}
 	for idx, item := range u.account.Data.Positions {
-		if item.Symbol == symbol {
+		if item.Key() == key {
 			u.account.Data.Positions[idx].PositionAmt = amount
 			u.account.Data.Positions[idx].EntryPrice = entryPrice
 			u.account.Data.Positions[idx].UnrealizedProfit = unPnl
-			if marginType == "isolated" {
-				if !u.account.Data.Positions[idx].Isolated {
-					u.account.Data.Positions[idx].Isolated = true
-				}
-			} else {
-				if u.account.Data.Positions[idx].Isolated {
-					u.account.Data.Positions[idx].Isolated = false
-				}
-			}
-			u.account.Data.Positions[idx].PositionSide = positionSide
-			break
+			u.account.Data.Positions[idx].Isolated = marginType == "isolated"
+			return
 		}
 	}
+	// not in the last snapshot yet
+	u.account.Data.Positions = append(u.account.Data.Positions, PositionsInAccount{
+		Symbol:           symbol,
+		PositionSide:     positionSide,
+		PositionAmt:      amount,
+		EntryPrice:       entryPrice,
+		UnrealizedProfit: unPnl,
+		Isolated:         marginType == "isolated",
+	})
 }
 
-func (c *Client) userData(ctx context.Context, listenKey string, logger *log.Logger, mainCh *chan map[string]interface{}) error {
+var errListenKeyExpired = errors.New("listen key expired")
+
+// between failed runs of maintainUserData
+var userDataRetry = RetryPolicy{BaseDelay: time.Second, MaxDelay: 30 * time.Second}
+
+// reconnect drops the listen key, connected is called once the websocket is up
+func (c *Client) userData(ctx context.Context, listenKey string, logger *log.Logger, mainCh *chan []byte, reconnect chan struct{}, connected func()) error {
 	var w wS
 	var duration time.Duration = 1810
 	w.Logger = logger
 	w.OnErr = false
 	var buffer bytes.Buffer
 	innerErr := make(chan error, 1)
-	buffer.WriteString("wss://fstream.apollox.finance/ws/")
+	expired := make(chan struct{})
+	// a leftover of the last connection
+	select {
+	case <-reconnect:
+	default:
+	}
+	buffer.WriteString(c.wsBaseURL)
+	buffer.WriteString("/ws/")
 	buffer.WriteString(listenKey)
 	url := buffer.String()
 	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
@@ -327,6 +380,7 @@ func (c *Client) userData(ctx context.Context, listenKey string, logger *log.Log
 		return err
 	}
 	w.Conn.SetPingHandler(nil)
+	connected()
 	go func() {
 		putKey := time.NewTicker(time.Minute * 30)
 		defer putKey.Stop()
@@ -336,8 +390,13 @@ func (c *Client) userData(ctx context.Context, listenKey string, logger *log.Log
 				return
 			case <-innerErr:
 				return
+			case <-reconnect:
+				close(expired)
+				// time out the read now
+				w.Conn.SetReadDeadline(time.Now())
+				return
 			case <-putKey.C:
-				if err := c.PutListenKey(listenKey); err != nil {
+				if err := c.PutListenKeyWithContext(ctx, listenKey); err != nil {
 					// time out in 1 sec
 					w.Conn.SetReadDeadline(time.Now().Add(time.Second))
 				}
@@ -366,22 +425,20 @@ func (c *Client) userData(ctx context.Context, listenKey string, logger *log.Log
 			}
 			_, buf, err := w.Conn.ReadMessage()
 			if err != nil {
+				select {
+				case <-expired:
+					log.Println("Apx User Data listen key expired, reconnect...")
+					return errListenKeyExpired
+				default:
+				}
 				w.outApxErr()
 				message := "Apx User Data reconnect..."
 				log.Println(message)
 				innerErr <- errors.New("restart")
 				return errors.New(message)
 			}
-			res, err1 := decodingMap(buf, logger)
-			if err1 != nil {
-				w.outApxErr()
-				message := "Apx User Data reconnect..."
-				log.Println(message, err1)
-				innerErr <- errors.New("restart")
-				return err1
-			}
-			// check event time first
-			handleUserData(&res, mainCh)
+			// decoded by maintainUserData
+			*mainCh <- buf
 			if err := w.Conn.SetReadDeadline(time.Now().Add(time.Second * duration)); err != nil {
 				innerErr <- errors.New("restart")
 				return err
@@ -392,21 +449,34 @@ func (c *Client) userData(ctx context.Context, listenKey string, logger *log.Log
 	}
 }
 
-func handleUserData(res *map[string]interface{}, mainCh *chan map[string]interface{}) {
-	if eventTimeUnix, ok := (*res)["E"].(float64); ok {
-		eventTime := formatingTimeStamp(eventTimeUnix)
-		if time.Now().After(eventTime.Add(time.Minute * 60)) {
-			return
-		}
-		// insert to chan
-		*mainCh <- *res
-	}
-}
-
 func (u *UserDataBranch) initialChannels() {
 	// 5 err is allowed
 	u.errs = make(chan error, 5)
-	u.trades = make(chan TradeData, 100)
+	u.marginCalls.alerts = make(chan MarginCallAlert, 10)
+}
+
+// after maintainUserData is done, so nothing writes to them anymore
+func (u *UserDataBranch) closeChannels() {
+	u.marginCalls.running.Wait()
+	close(u.errs)
+	close(u.marginCalls.alerts)
+	u.subs.closeAll()
+	// nobody may be reading ReadTrade anymore
+	u.trades.Lock()
+	if u.trades.sub != nil {
+		u.trades.sub.abandon()
+	}
+	u.trades.Unlock()
+}
+
+// the ReadTrade subscription, made on the first call
+func (u *UserDataBranch) tradeChannel() <-chan TradeData {
+	u.trades.Lock()
+	defer u.trades.Unlock()
+	if u.trades.ch == nil {
+		u.trades.ch, u.trades.sub = u.SubscribeTrades(SubscribeOpts{Policy: DeliverDropOldest, Buffer: 100})
+	}
+	return u.trades.ch
 }
 
 func (u *UserDataBranch) insertErr(input error) {
@@ -417,10 +487,7 @@ func (u *UserDataBranch) insertErr(input error) {
 }
 
 func (u *UserDataBranch) insertTrade(input *TradeData) {
-	if len(u.trades) == cap(u.trades) {
-		<-u.trades
-	}
-	u.trades <- *input
+	u.subs.publish(TopicTrades, *input)
 }
 
 func (u *UserDataBranch) readerrs() error {
@@ -433,6 +500,7 @@ func (u *UserDataBranch) readerrs() error {
 				buffer.WriteString(", ")
 			} else {
 				buffer.WriteString("errs chan already closed, ")
+				return errors.New(buffer.String())
 			}
 		default:
 			if buffer.Cap() == 0 {
@@ -0,0 +1,71 @@
+package appolloxapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestUserDataClosesChannelsWhenSnapshotInFlight(t *testing.T) {
+	inFlight := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case inFlight <- struct{}{}:
+		default:
+		}
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+	var u UserDataBranch
+	u.initialChannels()
+	u.initialStream()
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, _ := u.SubscribeTrades(SubscribeOpts{})
+	userData := make(chan []byte)
+	go u.keepUserData(ctx, New("key", "secret", "", WithBaseURL(srv.URL)), log.New(), &userData)
+	<-inFlight
+	cancel()
+	if list := readTrades(t, ch); len(list) != 0 {
+		t.Fatalf("got %d trades", len(list))
+	}
+	select {
+	case _, ok := <-u.MarginCalls():
+		if ok {
+			t.Fatal("got a margin call alert")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("margin call alerts not closed")
+	}
+	if _, err := u.ReadTrade(); err == nil {
+		t.Fatal("ReadTrade works after close")
+	}
+}
+
+func TestReadTradeSubscribesOnFirstCall(t *testing.T) {
+	var u UserDataBranch
+	if len(u.Subscriptions()) != 0 {
+		t.Fatal("subscribed before ReadTrade")
+	}
+	done := make(chan TradeData, 1)
+	go func() {
+		trade, _ := u.ReadTrade()
+		done <- trade
+	}()
+	deadline := time.Now().Add(time.Second)
+	for len(u.Subscriptions()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("ReadTrade did not subscribe")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	publishTrades(&u, 1)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("no trade read")
+	}
+}
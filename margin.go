@@ -0,0 +1,157 @@
+package appolloxapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+const (
+	MarginTypeIsolated = "ISOLATED"
+	MarginTypeCrossed  = "CROSSED"
+
+	// type of fapi/v1/positionMargin
+	PositionMarginAdd    = 1
+	PositionMarginReduce = 2
+)
+
+type MarginTypeOpts struct {
+	Symbol     string `url:"symbol"`
+	MarginType string `url:"marginType"`
+}
+
+// ISOLATED or CROSSED, returns ErrNoNeedToChangeMarginType when it is already set
+func (b *Client) ChangeMarginType(symbol, marginType string) error {
+	return b.ChangeMarginTypeWithContext(context.Background(), symbol, marginType)
+}
+
+func (b *Client) ChangeMarginTypeWithContext(ctx context.Context, symbol, marginType string) error {
+	opts := MarginTypeOpts{
+		Symbol:     strings.ToUpper(symbol),
+		MarginType: strings.ToUpper(marginType),
+	}
+	_, err := b.do(ctx, http.MethodPost, "fapi/v1/marginType", opts, true, false)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+type PositionMarginOpts struct {
+	Symbol       string `url:"symbol"`
+	PositionSide string `url:"positionSide,omitempty"`
+	Amount       string `url:"amount"`
+	Type         int    `url:"type"`
+}
+
+type PositionMarginResponse struct {
+	Amount float64 `json:"amount"`
+	Code   int     `json:"code"`
+	Msg    string  `json:"msg"`
+	Type   int     `json:"type"`
+}
+
+// isolated positions only, positionSide is LONG or SHORT in hedge mode
+func (b *Client) AddPositionMargin(symbol, positionSide, amount string) (*PositionMarginResponse, error) {
+	return b.AddPositionMarginWithContext(context.Background(), symbol, positionSide, amount)
+}
+
+func (b *Client) AddPositionMarginWithContext(ctx context.Context, symbol, positionSide, amount string) (*PositionMarginResponse, error) {
+	return b.ModifyPositionMarginWithContext(ctx, symbol, positionSide, amount, PositionMarginAdd)
+}
+
+func (b *Client) ReducePositionMargin(symbol, positionSide, amount string) (*PositionMarginResponse, error) {
+	return b.ReducePositionMarginWithContext(context.Background(), symbol, positionSide, amount)
+}
+
+func (b *Client) ReducePositionMarginWithContext(ctx context.Context, symbol, positionSide, amount string) (*PositionMarginResponse, error) {
+	return b.ModifyPositionMarginWithContext(ctx, symbol, positionSide, amount, PositionMarginReduce)
+}
+
+// marginType is PositionMarginAdd or PositionMarginReduce
+func (b *Client) ModifyPositionMargin(symbol, positionSide, amount string, marginType int) (*PositionMarginResponse, error) {
+	return b.ModifyPositionMarginWithContext(context.Background(), symbol, positionSide, amount, marginType)
+}
+
+func (b *Client) ModifyPositionMarginWithContext(ctx context.Context, symbol, positionSide, amount string, marginType int) (*PositionMarginResponse, error) {
+	opts := PositionMarginOpts{
+		Symbol:       strings.ToUpper(symbol),
+		PositionSide: strings.ToUpper(positionSide),
+		Amount:       amount,
+		Type:         marginType,
+	}
+	res, err := b.do(ctx, http.MethodPost, "fapi/v1/positionMargin", opts, true, false)
+	if err != nil {
+		return nil, err
+	}
+	resp := &PositionMarginResponse{}
+	err = json.Unmarshal(res, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type PositionMarginHistoryOpts struct {
+	Symbol    string `url:"symbol"`
+	Type      int    `url:"type,omitempty"`
+	StartTime int64  `url:"startTime,omitempty"`
+	EndTime   int64  `url:"endTime,omitempty"`
+	Limit     int    `url:"limit"`
+}
+
+type PositionMarginHistory struct {
+	Amount       string `json:"amount"`
+	Asset        string `json:"asset"`
+	Symbol       string `json:"symbol"`
+	Time         int64  `json:"time"`
+	Type         int    `json:"type"`
+	PositionSide string `json:"positionSide"`
+}
+
+// marginType 0 returns both adds and reduces, default limit is 500
+func (b *Client) PositionMarginHistory(symbol string, marginType, limit int, start, end int64) ([]*PositionMarginHistory, error) {
+	return b.PositionMarginHistoryWithContext(context.Background(), symbol, marginType, limit, start, end)
+}
+
+func (b *Client) PositionMarginHistoryWithContext(ctx context.Context, symbol string, marginType, limit int, start, end int64) ([]*PositionMarginHistory, error) {
+	opts := PositionMarginHistoryOpts{
+		Symbol: strings.ToUpper(symbol),
+		Type:   marginType,
+		Limit:  limit,
+	}
+	if start != 0 && end != 0 {
+		opts.StartTime = start
+		opts.EndTime = end
+	}
+	if opts.Limit == 0 {
+		opts.Limit = 500
+	}
+	res, err := b.do(ctx, http.MethodGet, "fapi/v1/positionMargin/history", opts, true, false)
+	if err != nil {
+		return nil, err
+	}
+	history := []*PositionMarginHistory{}
+	err = json.Unmarshal(res, &history)
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// set margin type and leverage of the symbol, safe to call at every startup
+func (b *Client) EnsureSymbolMargin(symbol, marginType string, leverage int) error {
+	return b.EnsureSymbolMarginWithContext(context.Background(), symbol, marginType, leverage)
+}
+
+func (b *Client) EnsureSymbolMarginWithContext(ctx context.Context, symbol, marginType string, leverage int) error {
+	if err := b.ChangeMarginTypeWithContext(ctx, symbol, marginType); err != nil && !errors.Is(err, ErrNoNeedToChangeMarginType) {
+		return err
+	}
+	// the exchange answers with the current state when the leverage is unchanged
+	if _, err := b.ChangeInitialLeverageWithContext(ctx, symbol, leverage); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package appolloxapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// time a margin call policy gets before it is canceled
+const marginCallPolicyTimeout = 30 * time.Second
+
+// one MARGIN_CALL event, liquidation is near for every position in it
+type MarginCallAlert struct {
+	Time               time.Time
+	CrossWalletBalance decimal.Decimal
+	// estimated from the positions in the event and the cross wallet balance, zero without any.
+	// the exchange counts every cross position, so it is not the account's margin ratio.
+	CrossMarginRatio decimal.Decimal
+	Positions        []MarginCallAlertPosition
+}
+
+type MarginCallAlertPosition struct {
+	MarginCallPosition
+	// maintenance margin over margin balance, liquidation at 1.
+	// the isolated wallet counts for isolated positions, the estimated cross ratio for cross ones.
+	MarginRatio decimal.Decimal
+}
+
+// runs on its own goroutine for every alert, an error shows up in AccountData.
+// see ReducePositionsPolicy and CancelOpenOrdersPolicy.
+type MarginCallPolicy func(ctx context.Context, client *Client, alert MarginCallAlert) error
+
+type marginCallBranch struct {
+	sync.RWMutex
+	alerts   chan MarginCallAlert
+	handlers []func(MarginCallAlert)
+	policy   MarginCallPolicy
+	// running policies, waited for before errs is closed
+	running sync.WaitGroup
+}
+
+// the ratios are estimates from the event alone, the other positions of the account are not in it
+func NewMarginCallAlert(event *MarginCallEvent) MarginCallAlert {
+	alert := MarginCallAlert{
+		Time:               event.EventTime(),
+		CrossWalletBalance: event.CrossWalletBalance,
+	}
+	crossMaint := decimal.Zero
+	crossBalance := event.CrossWalletBalance
+	hasCross := false
+	for _, position := range event.Positions {
+		if strings.EqualFold(position.MarginType, "isolated") {
+			continue
+		}
+		hasCross = true
+		crossMaint = crossMaint.Add(position.MaintenanceMargin)
+		crossBalance = crossBalance.Add(position.UnrealizedPnl)
+	}
+	if hasCross {
+		alert.CrossMarginRatio = marginRatio(crossMaint, crossBalance)
+	}
+	for _, position := range event.Positions {
+		item := MarginCallAlertPosition{MarginCallPosition: position}
+		if strings.EqualFold(position.MarginType, "isolated") {
+			item.MarginRatio = marginRatio(position.MaintenanceMargin, position.IsolatedWallet.Add(position.UnrealizedPnl))
+		} else {
+			item.MarginRatio = alert.CrossMarginRatio
+		}
+		alert.Positions = append(alert.Positions, item)
+	}
+	return alert
+}
+
+// symbols of the alert, each once
+func (a *MarginCallAlert) Symbols() []string {
+	seen := make(map[string]bool, len(a.Positions))
+	symbols := []string{}
+	for _, position := range a.Positions {
+		if !seen[position.Symbol] {
+			seen[position.Symbol] = true
+			symbols = append(symbols, position.Symbol)
+		}
+	}
+	return symbols
+}
+
+// margin call alerts ahead of every other event, the oldest are dropped when nobody reads them
+func (u *UserDataBranch) MarginCalls() <-chan MarginCallAlert {
+	return u.marginCalls.alerts
+}
+
+// called before the event reaches anything else
+func (u *UserDataBranch) OnMarginCall(handler func(MarginCallAlert)) {
+	u.marginCalls.Lock()
+	defer u.marginCalls.Unlock()
+	u.marginCalls.handlers = append(u.marginCalls.handlers, handler)
+}
+
+// automatic response to every alert, nil turns it off
+func (u *UserDataBranch) SetMarginCallPolicy(policy MarginCallPolicy) {
+	u.marginCalls.Lock()
+	defer u.marginCalls.Unlock()
+	u.marginCalls.policy = policy
+}
+
+// reduce-only market orders for percent of every position in the alert.
+// rules rounds the quantity to the step size, nil keeps the precision of the position.
+func ReducePositionsPolicy(percent decimal.Decimal, rules *ExchangeInfoCache) MarginCallPolicy {
+	return func(ctx context.Context, client *Client, alert MarginCallAlert) error {
+		if !percent.IsPositive() || percent.GreaterThan(decimal.NewFromInt(100)) {
+			return fmt.Errorf("reduce positions by %s%%: percent should be within (0, 100]", percent)
+		}
+		var firstErr error
+		for _, position := range alert.Positions {
+			if err := reducePosition(ctx, client, position.MarginCallPosition, percent, rules); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		return firstErr
+	}
+}
+
+// cancel every open order on the symbols of the alert, freeing their margin
+func CancelOpenOrdersPolicy() MarginCallPolicy {
+	return func(ctx context.Context, client *Client, alert MarginCallAlert) error {
+		var firstErr error
+		for _, symbol := range alert.Symbols() {
+			if _, err := client.CancelAllOrdersWithContext(ctx, symbol); err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("cancel open orders of %s: %w", symbol, err)
+			}
+		}
+		return firstErr
+	}
+}
+
+// internal funcs ------------------------------------------------
+
+func (u *UserDataBranch) handleMarginCall(ctx context.Context, event *MarginCallEvent) {
+	alert := NewMarginCallAlert(event)
+	u.marginCalls.RLock()
+	handlers := u.marginCalls.handlers
+	policy := u.marginCalls.policy
+	u.marginCalls.RUnlock()
+	for _, handler := range handlers {
+		handler(alert)
+	}
+	u.reportMarginCall(alert)
+	if policy == nil {
+		return
+	}
+	u.marginCalls.running.Add(1)
+	go func() {
+		defer u.marginCalls.running.Done()
+		policyCtx, cancel := context.WithTimeout(ctx, marginCallPolicyTimeout)
+		defer cancel()
+		if err := policy(policyCtx, u.client, alert); err != nil {
+			u.insertErr(fmt.Errorf("margin call policy: %w", err))
+		}
+	}()
+}
+
+func (u *UserDataBranch) reportMarginCall(alert MarginCallAlert) {
+	for {
+		select {
+		case u.marginCalls.alerts <- alert:
+			return
+		default:
+		}
+		select {
+		case <-u.marginCalls.alerts:
+		default:
+		}
+	}
+}
+
+func reducePosition(ctx context.Context, client *Client, position MarginCallPosition, percent decimal.Decimal, rules *ExchangeInfoCache) error {
+	qty := position.PositionAmt.Abs().Mul(percent).Div(decimal.NewFromInt(100))
+	if r, ok := rules.rulesOf(position.Symbol); ok {
+		qty = r.RoundQty(qty, true)
+	} else if exp := position.PositionAmt.Exponent(); exp < 0 {
+		qty = qty.Truncate(-exp)
+	} else {
+		qty = qty.Truncate(0)
+	}
+	if !qty.IsPositive() {
+		return nil
+	}
+	order := PlaceOrderOpts{
+		Symbol:       position.Symbol,
+		Type:         OrderTypeMarket,
+		Side:         "SELL",
+		Qty:          qty.String(),
+		PositionSide: position.PositionSide,
+	}
+	if position.PositionAmt.IsNegative() {
+		order.Side = "BUY"
+	}
+	if position.PositionSide == "" || position.PositionSide == PositionSideBoth {
+		order.ReduceOnly = "true"
+	}
+	if _, err := client.SubmitOrderWithContext(ctx, order); err != nil {
+		return fmt.Errorf("reduce %s %s by %s: %w", position.Symbol, position.PositionSide, qty, err)
+	}
+	return nil
+}
+
+// nil cache has no rules
+func (e *ExchangeInfoCache) rulesOf(symbol string) (*SymbolRules, bool) {
+	if e == nil {
+		return nil, false
+	}
+	return e.Rules(symbol)
+}
+
+// a margin balance at or below zero is already past liquidation
+func marginRatio(maintMargin, marginBalance decimal.Decimal) decimal.Decimal {
+	if !marginBalance.IsPositive() {
+		return decimal.NewFromInt(1)
+	}
+	return maintMargin.Div(marginBalance)
+}
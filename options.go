@@ -0,0 +1,46 @@
+package appolloxapi
+
+import (
+	"net/http"
+	"strings"
+)
+
+type ClientOption func(*Client)
+
+// REST base url, default is ENDPOINT
+func WithBaseURL(url string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(url, "/")
+	}
+}
+
+// websocket base url, default is WS_ENDPOINT
+func WithWSBaseURL(url string) ClientOption {
+	return func(c *Client) {
+		c.wsBaseURL = strings.TrimRight(url, "/")
+	}
+}
+
+// replace the default http client, which has a 10 sec timeout
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(c *Client) {
+		if hc != nil {
+			c.client = hc
+		}
+	}
+}
+
+// keep the default http client but use another transport
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		hc := *c.client
+		hc.Transport = transport
+		c.client = &hc
+	}
+}
+
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
@@ -1,104 +1,106 @@
 package appolloxapi
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"strings"
 )
 
+// see orderType.go for which fields each order type takes
 type PlaceOrderOpts struct {
-	Symbol      string `url:"symbol"`
-	Price       string `url:"price,omitempty"`
-	Qty         string `url:"quantity"`
-	TimeInForce string `url:"timeInForce,omitempty"`
-	Type        string `url:"type"`
-	Side        string `url:"side"`
-	ReduceOnly  string `url:"reduceOnly"`
+	Symbol           string `url:"symbol"`
+	Price            string `url:"price,omitempty"`
+	Qty              string `url:"quantity,omitempty"`
+	TimeInForce      string `url:"timeInForce,omitempty"`
+	Type             string `url:"type"`
+	Side             string `url:"side"`
+	ReduceOnly       string `url:"reduceOnly,omitempty"`
+	ClientID         string `url:"newClientOrderId,omitempty"`
+	StopPrice        string `url:"stopPrice,omitempty"`
+	ActivationPrice  string `url:"activationPrice,omitempty"`
+	CallbackRate     string `url:"callbackRate,omitempty"`
+	WorkingType      string `url:"workingType,omitempty"`
+	PriceProtect     string `url:"priceProtect,omitempty"`
+	ClosePosition    string `url:"closePosition,omitempty"`
+	NewOrderRespType string `url:"newOrderRespType,omitempty"`
+	PositionSide     string `url:"positionSide,omitempty"`
 }
 
-type PlaceOrderOptsMarket struct {
-	Symbol     string `url:"symbol"`
-	Qty        string `url:"quantity"`
-	Type       string `url:"type"`
-	Side       string `url:"side"`
-	ReduceOnly string `url:"reduceOnly"`
-	ClientID   string `url:"newClientOrderId,omitempty"`
+// see SubmitOrder for a client order id, the position side in hedge mode and every other field
+func (b *Client) PlaceOrderMarket(symbol, side string, size string, reduceOnly, clientID string) (*OrderResponse, error) {
+	return b.PlaceOrderMarketWithContext(context.Background(), symbol, side, size, reduceOnly, clientID)
 }
 
-func (b *Client) PlaceOrderMarket(symbol, side string, size string, reduceOnly, clientID string) (*OrderResponse, error) {
-	usymbol := strings.ToUpper(symbol)
-	uside := strings.ToUpper(side)
-	opts := PlaceOrderOptsMarket{
-		Symbol:     usymbol,
-		Side:       uside,
+func (b *Client) PlaceOrderMarketWithContext(ctx context.Context, symbol, side string, size string, reduceOnly, clientID string) (*OrderResponse, error) {
+	opts := PlaceOrderOpts{
+		Symbol:     symbol,
+		Side:       side,
 		Qty:        size,
-		Type:       "MARKET",
+		Type:       OrderTypeMarket,
 		ReduceOnly: reduceOnly,
+		ClientID:   clientID,
 	}
-	if clientID != "" {
-		opts.ClientID = clientID
-	}
-	res, err := b.do(http.MethodPost, "fapi/v1/order", opts, true, false)
-	if err != nil {
-		return nil, err
-	}
-	resp := &OrderResponse{}
-	err = json.Unmarshal(res, resp)
-	if err != nil {
-		return nil, err
-	}
-	return resp, nil
+	return b.SubmitOrderWithContext(ctx, opts)
 }
 
+// see SubmitOrder for a client order id, the position side in hedge mode and every other field
 func (b *Client) PlaceOrder(symbol, side string, price, size string, orderType, timeInforce, reduceOnly string) (*OrderResponse, error) {
-	usymbol := strings.ToUpper(symbol)
-	uside := strings.ToUpper(side)
-	utype := strings.ToUpper(orderType)
-	var utif string
-	if timeInforce == "" {
-		utif = "GTC"
-	} else {
-		utif = strings.ToUpper(timeInforce)
-	}
+	return b.PlaceOrderWithContext(context.Background(), symbol, side, price, size, orderType, timeInforce, reduceOnly)
+}
+
+func (b *Client) PlaceOrderWithContext(ctx context.Context, symbol, side string, price, size string, orderType, timeInforce, reduceOnly string) (*OrderResponse, error) {
 	opts := PlaceOrderOpts{
-		Symbol:      usymbol,
-		Side:        uside,
+		Symbol:      symbol,
+		Side:        side,
 		Price:       price,
 		Qty:         size,
-		Type:        utype,
-		TimeInForce: utif,
+		Type:        orderType,
+		TimeInForce: timeInforce,
 		ReduceOnly:  reduceOnly,
 	}
-	res, err := b.do(http.MethodPost, "fapi/v1/order", opts, true, false)
-	if err != nil {
+	return b.SubmitOrderWithContext(ctx, opts)
+}
+
+// any order type, the opts are validated before sending
+func (b *Client) SubmitOrder(opts PlaceOrderOpts) (*OrderResponse, error) {
+	return b.SubmitOrderWithContext(context.Background(), opts)
+}
+
+func (b *Client) SubmitOrderWithContext(ctx context.Context, opts PlaceOrderOpts) (*OrderResponse, error) {
+	opts.normalize()
+	if err := opts.validate(); err != nil {
 		return nil, err
 	}
-	resp := &OrderResponse{}
-	err = json.Unmarshal(res, resp)
-	if err != nil {
-		return nil, err
+	if opts.ClientID == "" {
+		opts.ClientID = newClientOrderID()
 	}
-	return resp, nil
+	return b.submitOrder(ctx, opts.Symbol, opts.ClientID, opts)
 }
 
 type PlaceBatchOrdersOptsSwap struct {
 	OrderList string `url:"batchOrders"`
 }
 
-// max 5 orders per request
+// max 5 orders per request, see PlaceOrdersInBatches for more
 func (b *Client) PlaceBatchOrders(orders []PlaceOrderOpts) (*BatchOrdersResponse, error) {
+	return b.PlaceBatchOrdersWithContext(context.Background(), orders)
+}
+
+func (b *Client) PlaceBatchOrdersWithContext(ctx context.Context, orders []PlaceOrderOpts) (*BatchOrdersResponse, error) {
+	if err := checkBatchSize(len(orders), maxBatchPlaceOrders); err != nil {
+		return nil, err
+	}
 	opts := []map[string]interface{}{}
-	for _, order := range orders {
-		m := map[string]interface{}{}
-		m["symbol"] = strings.ToUpper(order.Symbol)
-		m["side"] = strings.ToUpper(order.Side)
-		m["type"] = strings.ToUpper(order.Type)
-		if m["type"] == "LIMIT" {
-			m["timeInForce"] = strings.ToUpper(order.TimeInForce)
-			m["price"] = order.Price
+	for idx, order := range orders {
+		order.normalize()
+		if err := order.validate(); err != nil {
+			return nil, fmt.Errorf("batch order %d: %w", idx, err)
 		}
-		m["quantity"] = order.Qty
-		m["reduceOnly"] = strings.ToLower(order.ReduceOnly)
-		opts = append(opts, m)
+		if order.ClientID == "" {
+			order.ClientID = newClientOrderID()
+		}
+		opts = append(opts, order.batchParams())
 	}
 	out, err := json.Marshal(opts)
 	if err != nil {
@@ -106,7 +108,7 @@ func (b *Client) PlaceBatchOrders(orders []PlaceOrderOpts) (*BatchOrdersResponse
 	}
 	input := PlaceBatchOrdersOptsSwap{}
 	input.OrderList = Bytes2String(out)
-	res, err := b.do(http.MethodPost, "fapi/v1/batchOrders", input, true, false)
+	res, err := b.do(ctx, http.MethodPost, "fapi/v1/batchOrders", input, true, false)
 	if err != nil {
 		return nil, err
 	}
@@ -123,32 +125,35 @@ type OrderResponse struct {
 	CumQty        string `json:"cumQty"`
 	CumQuote      string `json:"cumQuote"`
 	ExecutedQty   string `json:"executedQty"`
-	OrderID       int    `json:"orderId"`
-	AvgPrice      string `json:"avgPrice, omitempty"`
+	OrderID       int64  `json:"orderId"`
+	AvgPrice      string `json:"avgPrice,omitempty"`
 	OrigQty       string `json:"origQty"`
 	Price         string `json:"price"`
 	ReduceOnly    bool   `json:"reduceOnly"`
 	Side          string `json:"side"`
 	PositionSide  string `json:"positionSide"`
 	Status        string `json:"status"`
-	StopPrice     string `json:"stopPrice, omitempty"`
-	ClosePosition bool   `json:"closePosition, omitempty"`
+	StopPrice     string `json:"stopPrice,omitempty"`
+	ClosePosition bool   `json:"closePosition,omitempty"`
 	Symbol        string `json:"symbol"`
 	TimeInForce   string `json:"timeInForce"`
 	Type          string `json:"type"`
 	OrigType      string `json:"origType"`
-	ActivatePrice string `json:"activatePrice, omitempty"`
-	PriceRate     string `json:"priceRate, omitempty"`
+	ActivatePrice string `json:"activatePrice,omitempty"`
+	PriceRate     string `json:"priceRate,omitempty"`
 	UpdateTime    int64  `json:"updateTime"`
 	WorkingType   string `json:"workingType"`
 }
 
-type BatchOrdersResponse []struct {
+type BatchOrdersResponse []BatchOrderResult
+
+// Code and Msg are set when the item failed, see Err
+type BatchOrderResult struct {
 	Clientorderid string `json:"clientOrderId,omitempty"`
 	Cumqty        string `json:"cumQty,omitempty"`
 	Cumquote      string `json:"cumQuote,omitempty"`
 	Executedqty   string `json:"executedQty,omitempty"`
-	Orderid       int    `json:"orderId,omitempty"`
+	Orderid       int64  `json:"orderId,omitempty"`
 	Avgprice      string `json:"avgPrice,omitempty"`
 	Origqty       string `json:"origQty,omitempty"`
 	Price         string `json:"price,omitempty"`
@@ -170,31 +175,54 @@ type BatchOrdersResponse []struct {
 	Msg           string `json:"msg,omitempty"`
 }
 
-func (b *Client) CancelOrder(symbol string, oid int) (*OrderResponse, error) {
-	usymbol := strings.ToUpper(symbol)
+func (b *Client) CancelOrder(symbol string, oid int64) (*OrderResponse, error) {
+	return b.CancelOrderWithContext(context.Background(), symbol, oid)
+}
+
+func (b *Client) CancelOrderWithContext(ctx context.Context, symbol string, oid int64) (*OrderResponse, error) {
 	opts := OIDOpts{
-		Symbol: usymbol,
+		Symbol: strings.ToUpper(symbol),
 		Oid:    oid,
 	}
-	res, err := b.do(http.MethodDelete, "fapi/v1/order", opts, true, false)
-	if err != nil {
+	resp := &OrderResponse{}
+	if err := b.orderRequest(ctx, http.MethodDelete, "fapi/v1/order", opts, resp); err != nil {
 		return nil, err
 	}
+	return resp, nil
+}
+
+func (b *Client) CancelOrderByClientID(symbol, clientID string) (*OrderResponse, error) {
+	return b.CancelOrderByClientIDWithContext(context.Background(), symbol, clientID)
+}
+
+func (b *Client) CancelOrderByClientIDWithContext(ctx context.Context, symbol, clientID string) (*OrderResponse, error) {
+	opts := OIDOpts{
+		Symbol:            strings.ToUpper(symbol),
+		OrigClientOrderID: clientID,
+	}
 	resp := &OrderResponse{}
-	err = json.Unmarshal(res, resp)
-	if err != nil {
+	if err := b.orderRequest(ctx, http.MethodDelete, "fapi/v1/order", opts, resp); err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
 
+// one of the lists is set
 type OIDListOpts struct {
-	Symbol string `url:"symbol"`
-	Oid    string `url:"orderIdList"`
+	Symbol    string `url:"symbol"`
+	Oid       string `url:"orderIdList,omitempty"`
+	ClientIDs string `url:"origClientOrderIdList,omitempty"`
+}
+
+// max 10 order per request, see CancelOrdersInBatches for more
+func (b *Client) CancelBatchOrders(symbol string, oids []int64) (*BatchOrdersResponse, error) {
+	return b.CancelBatchOrdersWithContext(context.Background(), symbol, oids)
 }
 
-// max 10 order per request
-func (b *Client) CancelBatchOrders(symbol string, oids []int) (*BatchOrdersResponse, error) {
+func (b *Client) CancelBatchOrdersWithContext(ctx context.Context, symbol string, oids []int64) (*BatchOrdersResponse, error) {
+	if err := checkBatchSize(len(oids), maxBatchCancelOrders); err != nil {
+		return nil, err
+	}
 	out, err := json.Marshal(oids)
 	if err != nil {
 		return nil, err
@@ -203,31 +231,56 @@ func (b *Client) CancelBatchOrders(symbol string, oids []int) (*BatchOrdersRespo
 		Symbol: strings.ToUpper(symbol),
 		Oid:    Bytes2String(out),
 	}
-	res, err := b.do(http.MethodDelete, "fapi/v1/batchOrders", opts, true, false)
-	if err != nil {
+	return b.cancelBatchOrders(ctx, opts)
+}
+
+// max 10 order per request, see CancelOrdersByClientIDInBatches for more
+func (b *Client) CancelBatchOrdersByClientID(symbol string, clientIDs []string) (*BatchOrdersResponse, error) {
+	return b.CancelBatchOrdersByClientIDWithContext(context.Background(), symbol, clientIDs)
+}
+
+func (b *Client) CancelBatchOrdersByClientIDWithContext(ctx context.Context, symbol string, clientIDs []string) (*BatchOrdersResponse, error) {
+	if err := checkBatchSize(len(clientIDs), maxBatchCancelOrders); err != nil {
 		return nil, err
 	}
-	resp := BatchOrdersResponse{}
-	err = json.Unmarshal(res, &resp)
+	out, err := json.Marshal(clientIDs)
 	if err != nil {
 		return nil, err
 	}
-	return &resp, nil
+	opts := OIDListOpts{
+		Symbol:    strings.ToUpper(symbol),
+		ClientIDs: Bytes2String(out),
+	}
+	return b.cancelBatchOrders(ctx, opts)
 }
 
-func (b *Client) OpenOrder(symbol string, oid int) (*OrderResponse, error) {
-	usymbol := strings.ToUpper(symbol)
+func (b *Client) OpenOrder(symbol string, oid int64) (*OrderResponse, error) {
+	return b.OpenOrderWithContext(context.Background(), symbol, oid)
+}
+
+func (b *Client) OpenOrderWithContext(ctx context.Context, symbol string, oid int64) (*OrderResponse, error) {
 	opts := OIDOpts{
-		Symbol: usymbol,
+		Symbol: strings.ToUpper(symbol),
 		Oid:    oid,
 	}
-	res, err := b.do(http.MethodGet, "fapi/v1/openOrder", opts, true, false)
-	if err != nil {
+	resp := &OrderResponse{}
+	if err := b.orderRequest(ctx, http.MethodGet, "fapi/v1/openOrder", opts, resp); err != nil {
 		return nil, err
 	}
+	return resp, nil
+}
+
+func (b *Client) OpenOrderByClientID(symbol, clientID string) (*OrderResponse, error) {
+	return b.OpenOrderByClientIDWithContext(context.Background(), symbol, clientID)
+}
+
+func (b *Client) OpenOrderByClientIDWithContext(ctx context.Context, symbol, clientID string) (*OrderResponse, error) {
+	opts := OIDOpts{
+		Symbol:            strings.ToUpper(symbol),
+		OrigClientOrderID: clientID,
+	}
 	resp := &OrderResponse{}
-	err = json.Unmarshal(res, resp)
-	if err != nil {
+	if err := b.orderRequest(ctx, http.MethodGet, "fapi/v1/openOrder", opts, resp); err != nil {
 		return nil, err
 	}
 	return resp, nil
@@ -238,7 +291,7 @@ type QueryOrderResonse struct {
 	ClientOrderID string `json:"clientOrderId"`
 	CumQuote      string `json:"cumQuote"`
 	ExecutedQty   string `json:"executedQty"`
-	OrderID       int    `json:"orderId"`
+	OrderID       int64  `json:"orderId"`
 	OrigQty       string `json:"origQty"`
 	OrigType      string `json:"origType"`
 	Price         string `json:"price"`
@@ -259,25 +312,67 @@ type QueryOrderResonse struct {
 	PriceProtect  bool   `json:"priceProtect"`
 }
 
+// either Oid or OrigClientOrderID is set
 type OIDOpts struct {
-	Symbol   string `url:"symbol"`
-	Oid      int    `url:"orderId"`
-	Isolated string `url:"isIsolated, omitempty"`
+	Symbol            string `url:"symbol"`
+	Oid               int64  `url:"orderId,omitempty"`
+	OrigClientOrderID string `url:"origClientOrderId,omitempty"`
+	Isolated          string `url:"isIsolated,omitempty"`
 }
 
-func (b *Client) QueryOrder(symbol string, oid int) (*QueryOrderResonse, error) {
-	usymbol := strings.ToUpper(symbol)
+func (q *QueryOrderResonse) orderResponse() *OrderResponse {
+	return &OrderResponse{
+		ClientOrderID: q.ClientOrderID,
+		CumQuote:      q.CumQuote,
+		ExecutedQty:   q.ExecutedQty,
+		OrderID:       q.OrderID,
+		AvgPrice:      q.AvgPrice,
+		OrigQty:       q.OrigQty,
+		Price:         q.Price,
+		ReduceOnly:    q.ReduceOnly,
+		Side:          q.Side,
+		PositionSide:  q.PositionSide,
+		Status:        q.Status,
+		StopPrice:     q.StopPrice,
+		ClosePosition: q.ClosePosition,
+		Symbol:        q.Symbol,
+		TimeInForce:   q.TimeInForce,
+		Type:          q.Type,
+		OrigType:      q.OrigType,
+		ActivatePrice: q.ActivatePrice,
+		PriceRate:     q.PriceRate,
+		UpdateTime:    q.UpdateTime,
+		WorkingType:   q.WorkingType,
+	}
+}
+
+func (b *Client) QueryOrder(symbol string, oid int64) (*QueryOrderResonse, error) {
+	return b.QueryOrderWithContext(context.Background(), symbol, oid)
+}
+
+func (b *Client) QueryOrderWithContext(ctx context.Context, symbol string, oid int64) (*QueryOrderResonse, error) {
 	opts := OIDOpts{
-		Symbol: usymbol,
+		Symbol: strings.ToUpper(symbol),
 		Oid:    oid,
 	}
-	res, err := b.do(http.MethodGet, "fapi/v1/order", opts, true, false)
-	if err != nil {
+	resp := &QueryOrderResonse{}
+	if err := b.orderRequest(ctx, http.MethodGet, "fapi/v1/order", opts, resp); err != nil {
 		return nil, err
 	}
+	return resp, nil
+}
+
+func (b *Client) QueryOrderByClientID(symbol, clientID string) (*QueryOrderResonse, error) {
+	return b.QueryOrderByClientIDWithContext(context.Background(), symbol, clientID)
+}
+
+func (b *Client) QueryOrderByClientIDWithContext(ctx context.Context, symbol, clientID string) (*QueryOrderResonse, error) {
+	opts := OIDOpts{
+		Symbol:            strings.ToUpper(symbol),
+		OrigClientOrderID: clientID,
+	}
 	resp := &QueryOrderResonse{}
-	err = json.Unmarshal(res, resp)
-	if err != nil {
+	if err := b.orderRequest(ctx, http.MethodGet, "fapi/v1/order", opts, resp); err != nil {
 		return nil, err
 	}
 	return resp, nil
@@ -288,7 +383,7 @@ type CurrentOpenOrdersResponse struct {
 	Clientorderid string `json:"clientOrderId"`
 	Cumquote      string `json:"cumQuote"`
 	Executedqty   string `json:"executedQty"`
-	Orderid       int    `json:"orderId"`
+	Orderid       int64  `json:"orderId"`
 	Origqty       string `json:"origQty"`
 	Origtype      string `json:"origType"`
 	Price         string `json:"price"`
@@ -309,12 +404,17 @@ type CurrentOpenOrdersResponse struct {
 	Priceprotect  bool   `json:"priceProtect"`
 }
 
+// empty symbol for every symbol, weight 40
 func (b *Client) GetCurrentOrders(symbol string) ([]CurrentOpenOrdersResponse, error) {
+	return b.GetCurrentOrdersWithContext(context.Background(), symbol)
+}
+
+func (b *Client) GetCurrentOrdersWithContext(ctx context.Context, symbol string) ([]CurrentOpenOrdersResponse, error) {
 	usymbol := strings.ToUpper(symbol)
 	opts := onlySymbolOpts{
 		Symbol: usymbol,
 	}
-	res, err := b.do(http.MethodGet, "fapi/v1/openOrders", opts, true, false)
+	res, err := b.do(ctx, http.MethodGet, "fapi/v1/openOrders", opts, true, false)
 	if err != nil {
 		return nil, err
 	}
@@ -332,11 +432,15 @@ type CancelAllOrdersResponse struct {
 }
 
 func (b *Client) CancelAllOrders(symbol string) (*CancelAllOrdersResponse, error) {
+	return b.CancelAllOrdersWithContext(context.Background(), symbol)
+}
+
+func (b *Client) CancelAllOrdersWithContext(ctx context.Context, symbol string) (*CancelAllOrdersResponse, error) {
 	usymbol := strings.ToUpper(symbol)
 	opts := onlySymbolOpts{
 		Symbol: usymbol,
 	}
-	res, err := b.do(http.MethodDelete, "fapi/v1/allOpenOrders", opts, true, false)
+	res, err := b.do(ctx, http.MethodDelete, "fapi/v1/allOpenOrders", opts, true, false)
 	if err != nil {
 		return nil, err
 	}
@@ -347,3 +451,26 @@ func (b *Client) CancelAllOrders(symbol string) (*CancelAllOrdersResponse, error
 	}
 	return resp, nil
 }
+
+// internal funcs ------------------------------------------------
+
+func (b *Client) orderRequest(ctx context.Context, method, path string, opts OIDOpts, out interface{}) error {
+	res, err := b.do(ctx, method, path, opts, true, false)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(res, out)
+}
+
+func (b *Client) cancelBatchOrders(ctx context.Context, opts OIDListOpts) (*BatchOrdersResponse, error) {
+	res, err := b.do(ctx, http.MethodDelete, "fapi/v1/batchOrders", opts, true, false)
+	if err != nil {
+		return nil, err
+	}
+	resp := BatchOrdersResponse{}
+	err = json.Unmarshal(res, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
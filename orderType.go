@@ -0,0 +1,249 @@
+package appolloxapi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	OrderTypeLimit              = "LIMIT"
+	OrderTypeMarket             = "MARKET"
+	OrderTypeStop               = "STOP"
+	OrderTypeStopMarket         = "STOP_MARKET"
+	OrderTypeTakeProfit         = "TAKE_PROFIT"
+	OrderTypeTakeProfitMarket   = "TAKE_PROFIT_MARKET"
+	OrderTypeTrailingStopMarket = "TRAILING_STOP_MARKET"
+
+	WorkingTypeMarkPrice     = "MARK_PRICE"
+	WorkingTypeContractPrice = "CONTRACT_PRICE"
+
+	OrderRespTypeAck    = "ACK"
+	OrderRespTypeResult = "RESULT"
+
+	// BOTH in one-way mode, LONG or SHORT in hedge mode
+	PositionSideBoth  = "BOTH"
+	PositionSideLong  = "LONG"
+	PositionSideShort = "SHORT"
+)
+
+var ErrInvalidOrder = errors.New("invalid order")
+
+// callbackRate range of TRAILING_STOP_MARKET, in percent
+var (
+	minCallbackRate = decimal.NewFromFloat(0.1)
+	maxCallbackRate = decimal.NewFromInt(5)
+)
+
+// internal funcs ------------------------------------------------
+
+// upper case the enums, lower case the bools, and fill the default time in force
+func (o *PlaceOrderOpts) normalize() {
+	o.Symbol = strings.ToUpper(o.Symbol)
+	o.Side = strings.ToUpper(o.Side)
+	o.Type = strings.ToUpper(o.Type)
+	o.PositionSide = strings.ToUpper(o.PositionSide)
+	o.WorkingType = strings.ToUpper(o.WorkingType)
+	o.NewOrderRespType = strings.ToUpper(o.NewOrderRespType)
+	o.ReduceOnly = strings.ToLower(o.ReduceOnly)
+	o.ClosePosition = strings.ToLower(o.ClosePosition)
+	o.PriceProtect = strings.ToUpper(o.PriceProtect)
+	if (o.PositionSide == PositionSideLong || o.PositionSide == PositionSideShort) && o.ReduceOnly == "false" {
+		// can not be sent in hedge mode, even as false
+		o.ReduceOnly = ""
+	}
+	switch o.Type {
+	case OrderTypeLimit, OrderTypeStop, OrderTypeTakeProfit:
+		if o.TimeInForce == "" {
+			o.TimeInForce = "GTC"
+		}
+		o.TimeInForce = strings.ToUpper(o.TimeInForce)
+	default:
+		// only used by the limit types
+		o.TimeInForce = ""
+	}
+}
+
+// check the parameter combination of each order type before sending it, call normalize first
+func (o *PlaceOrderOpts) validate() error {
+	if o.Symbol == "" {
+		return invalidOrder("symbol is required")
+	}
+	if o.Side != "BUY" && o.Side != "SELL" {
+		return invalidOrder("side should be BUY or SELL, got %q", o.Side)
+	}
+	for _, field := range []struct {
+		name, value string
+	}{
+		{"price", o.Price},
+		{"quantity", o.Qty},
+		{"stopPrice", o.StopPrice},
+		{"activationPrice", o.ActivationPrice},
+		{"callbackRate", o.CallbackRate},
+	} {
+		if field.value == "" {
+			continue
+		}
+		value, err := decimal.NewFromString(field.value)
+		if err != nil {
+			return invalidOrder("%s %q is not a number", field.name, field.value)
+		}
+		if !value.IsPositive() {
+			return invalidOrder("%s should be positive, got %s", field.name, field.value)
+		}
+	}
+	if o.ReduceOnly != "" && o.ReduceOnly != "true" && o.ReduceOnly != "false" {
+		return invalidOrder("reduceOnly should be true or false, got %q", o.ReduceOnly)
+	}
+	if o.ClosePosition != "" && o.ClosePosition != "true" && o.ClosePosition != "false" {
+		return invalidOrder("closePosition should be true or false, got %q", o.ClosePosition)
+	}
+	if o.PriceProtect != "" && o.PriceProtect != "TRUE" && o.PriceProtect != "FALSE" {
+		return invalidOrder("priceProtect should be TRUE or FALSE, got %q", o.PriceProtect)
+	}
+	switch o.WorkingType {
+	case "", WorkingTypeMarkPrice, WorkingTypeContractPrice:
+	default:
+		return invalidOrder("unknown workingType %q", o.WorkingType)
+	}
+	switch o.PositionSide {
+	case "", PositionSideBoth:
+	case PositionSideLong, PositionSideShort:
+		// hedge mode closes by the opposite side, reduceOnly is rejected
+		if o.ReduceOnly == "true" {
+			return invalidOrder("reduceOnly can not be used with positionSide %s", o.PositionSide)
+		}
+	default:
+		return invalidOrder("unknown positionSide %q", o.PositionSide)
+	}
+	switch o.NewOrderRespType {
+	case "", OrderRespTypeAck, OrderRespTypeResult:
+	default:
+		return invalidOrder("unknown newOrderRespType %q", o.NewOrderRespType)
+	}
+	closePosition := o.ClosePosition == "true"
+	conditional := false
+	switch o.Type {
+	case OrderTypeLimit:
+		if err := o.require("price", o.Price, "quantity", o.Qty); err != nil {
+			return err
+		}
+		if err := o.forbid("stopPrice", o.StopPrice); err != nil {
+			return err
+		}
+	case OrderTypeMarket:
+		if err := o.require("quantity", o.Qty); err != nil {
+			return err
+		}
+		if err := o.forbid("price", o.Price, "stopPrice", o.StopPrice); err != nil {
+			return err
+		}
+	case OrderTypeStop, OrderTypeTakeProfit:
+		conditional = true
+		if err := o.require("price", o.Price, "quantity", o.Qty, "stopPrice", o.StopPrice); err != nil {
+			return err
+		}
+	case OrderTypeStopMarket, OrderTypeTakeProfitMarket:
+		conditional = true
+		if err := o.require("stopPrice", o.StopPrice); err != nil {
+			return err
+		}
+		if err := o.forbid("price", o.Price); err != nil {
+			return err
+		}
+		if closePosition {
+			if err := o.forbid("quantity", o.Qty); err != nil {
+				return err
+			}
+			if o.ReduceOnly == "true" {
+				return invalidOrder("closePosition can not be used with reduceOnly")
+			}
+		} else if err := o.require("quantity", o.Qty); err != nil {
+			return err
+		}
+	case OrderTypeTrailingStopMarket:
+		conditional = true
+		if err := o.require("quantity", o.Qty, "callbackRate", o.CallbackRate); err != nil {
+			return err
+		}
+		if err := o.forbid("price", o.Price, "stopPrice", o.StopPrice); err != nil {
+			return err
+		}
+		rate, _ := decimal.NewFromString(o.CallbackRate)
+		if rate.LessThan(minCallbackRate) || rate.GreaterThan(maxCallbackRate) {
+			return invalidOrder("callbackRate should be between %s and %s, got %s", minCallbackRate, maxCallbackRate, o.CallbackRate)
+		}
+	default:
+		return invalidOrder("unknown order type %q", o.Type)
+	}
+	if o.Type != OrderTypeTrailingStopMarket {
+		if err := o.forbid("activationPrice", o.ActivationPrice, "callbackRate", o.CallbackRate); err != nil {
+			return err
+		}
+	}
+	if o.Type != OrderTypeStopMarket && o.Type != OrderTypeTakeProfitMarket && o.ClosePosition != "" {
+		return invalidOrder("closePosition is only for %s and %s", OrderTypeStopMarket, OrderTypeTakeProfitMarket)
+	}
+	if !conditional {
+		if err := o.forbid("workingType", o.WorkingType, "priceProtect", o.PriceProtect); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pairs of name and value
+func (o *PlaceOrderOpts) require(fields ...string) error {
+	for i := 0; i+1 < len(fields); i += 2 {
+		if fields[i+1] == "" {
+			return invalidOrder("%s is required for %s order", fields[i], o.Type)
+		}
+	}
+	return nil
+}
+
+// pairs of name and value
+func (o *PlaceOrderOpts) forbid(fields ...string) error {
+	for i := 0; i+1 < len(fields); i += 2 {
+		if fields[i+1] != "" {
+			return invalidOrder("%s is not allowed for %s order", fields[i], o.Type)
+		}
+	}
+	return nil
+}
+
+// the batchOrders payload, same keys as a single order
+func (o *PlaceOrderOpts) batchParams() map[string]interface{} {
+	m := map[string]interface{}{
+		"symbol": o.Symbol,
+		"side":   o.Side,
+		"type":   o.Type,
+	}
+	optional := map[string]string{
+		"price":            o.Price,
+		"quantity":         o.Qty,
+		"timeInForce":      o.TimeInForce,
+		"reduceOnly":       o.ReduceOnly,
+		"newClientOrderId": o.ClientID,
+		"positionSide":     o.PositionSide,
+		"stopPrice":        o.StopPrice,
+		"activationPrice":  o.ActivationPrice,
+		"callbackRate":     o.CallbackRate,
+		"workingType":      o.WorkingType,
+		"priceProtect":     o.PriceProtect,
+		"closePosition":    o.ClosePosition,
+		"newOrderRespType": o.NewOrderRespType,
+	}
+	for key, value := range optional {
+		if value != "" {
+			m[key] = value
+		}
+	}
+	return m
+}
+
+func invalidOrder(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidOrder, fmt.Sprintf(format, args...))
+}
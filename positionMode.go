@@ -0,0 +1,90 @@
+package appolloxapi
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+type PositionModeResponse struct {
+	DualSidePosition bool `json:"dualSidePosition"`
+}
+
+type PositionModeOpts struct {
+	DualSidePosition string `url:"dualSidePosition"`
+}
+
+// true is hedge mode, false is one-way mode
+func (b *Client) GetPositionMode() (bool, error) {
+	return b.GetPositionModeWithContext(context.Background())
+}
+
+func (b *Client) GetPositionModeWithContext(ctx context.Context) (bool, error) {
+	res, err := b.do(ctx, http.MethodGet, "fapi/v1/positionSide/dual", nil, true, false)
+	if err != nil {
+		return false, err
+	}
+	resp := &PositionModeResponse{}
+	err = json.Unmarshal(res, resp)
+	if err != nil {
+		return false, err
+	}
+	return resp.DualSidePosition, nil
+}
+
+// applies to every symbol, the exchange refuses while any position or open order exists
+func (b *Client) ChangePositionMode(dualSide bool) error {
+	return b.ChangePositionModeWithContext(context.Background(), dualSide)
+}
+
+func (b *Client) ChangePositionModeWithContext(ctx context.Context, dualSide bool) error {
+	opts := PositionModeOpts{
+		DualSidePosition: strconv.FormatBool(dualSide),
+	}
+	_, err := b.do(ctx, http.MethodPost, "fapi/v1/positionSide/dual", opts, true, false)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// positions are unique by symbol and side, side is BOTH in one-way mode
+type PositionKey struct {
+	Symbol       string
+	PositionSide string
+}
+
+func (p PositionsInAccount) Key() PositionKey {
+	return PositionKey{Symbol: p.Symbol, PositionSide: p.PositionSide}
+}
+
+func (p PositionResponse) Key() PositionKey {
+	return PositionKey{Symbol: p.Symbol, PositionSide: p.PositionSide}
+}
+
+func (a *AccountResponse) Position(symbol, positionSide string) (PositionsInAccount, bool) {
+	key := PositionKey{Symbol: symbol, PositionSide: positionSide}
+	for _, position := range a.Positions {
+		if position.Key() == key {
+			return position, true
+		}
+	}
+	return PositionsInAccount{}, false
+}
+
+func (a *AccountResponse) PositionMap() map[PositionKey]PositionsInAccount {
+	m := make(map[PositionKey]PositionsInAccount, len(a.Positions))
+	for _, position := range a.Positions {
+		m[position.Key()] = position
+	}
+	return m
+}
+
+// key the result of Positions by symbol and side
+func PositionMap(positions []*PositionResponse) map[PositionKey]*PositionResponse {
+	m := make(map[PositionKey]*PositionResponse, len(positions))
+	for _, position := range positions {
+		m[position.Key()] = position
+	}
+	return m
+}
@@ -0,0 +1,374 @@
+package appolloxapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type RateLimitMode int
+
+const (
+	// wait until the budget is back, or the ctx is done
+	RateLimitBlock RateLimitMode = iota
+	// return ErrRateLimited right away
+	RateLimitFailFast
+)
+
+var ErrRateLimited = errors.New("apx client side rate limit reached")
+
+// default limits until exchange info is loaded
+const (
+	defaultWeightLimit1m = 2400
+	defaultOrderLimit10s = 300
+	defaultOrderLimit1m  = 1200
+	// futures/data endpoints are limited by request count on their own
+	defaultDataLimit5m    = 1000
+	defaultLimitThreshold = 0.9
+)
+
+type RateLimitUsage struct {
+	UsedWeight1m  int
+	WeightLimit1m int
+	OrderCount10s int
+	OrderLimit10s int
+	OrderCount1m  int
+	OrderLimit1m  int
+	DataCount5m   int
+	DataLimit5m   int
+	BannedUntil   time.Time
+}
+
+type rateLimiter struct {
+	mux         sync.Mutex
+	mode        RateLimitMode
+	threshold   float64
+	weight      usageWindow
+	orders10s   usageWindow
+	orders1m    usageWindow
+	data5m      usageWindow
+	bannedUntil time.Time
+}
+
+type usageWindow struct {
+	interval time.Duration
+	limit    int
+	used     int
+	start    time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		mode:      RateLimitBlock,
+		threshold: defaultLimitThreshold,
+		weight:    usageWindow{interval: time.Minute, limit: defaultWeightLimit1m},
+		orders10s: usageWindow{interval: 10 * time.Second, limit: defaultOrderLimit10s},
+		orders1m:  usageWindow{interval: time.Minute, limit: defaultOrderLimit1m},
+		data5m:    usageWindow{interval: 5 * time.Minute, limit: defaultDataLimit5m},
+	}
+}
+
+// block is the default mode
+func (c *Client) SetRateLimitMode(mode RateLimitMode) {
+	c.limiter.mux.Lock()
+	defer c.limiter.mux.Unlock()
+	c.limiter.mode = mode
+}
+
+// share of each limit the client allows itself to use, default is 0.9
+func (c *Client) SetRateLimitThreshold(threshold float64) {
+	if threshold <= 0 || threshold > 1 {
+		return
+	}
+	c.limiter.mux.Lock()
+	defer c.limiter.mux.Unlock()
+	c.limiter.threshold = threshold
+}
+
+// take the limits from GetExchangeInfoResponse.RateLimits
+func (c *Client) SetRateLimits(limits []RateLimit) {
+	c.limiter.mux.Lock()
+	defer c.limiter.mux.Unlock()
+	for _, limit := range limits {
+		interval := limit.duration()
+		switch limit.RateLimitType {
+		case "REQUEST_WEIGHT":
+			if interval == time.Minute {
+				c.limiter.weight.limit = limit.Limit
+			}
+		case "ORDERS":
+			switch interval {
+			case 10 * time.Second:
+				c.limiter.orders10s.limit = limit.Limit
+			case time.Minute:
+				c.limiter.orders1m.limit = limit.Limit
+			}
+		}
+	}
+}
+
+// fetch exchange info and apply its rate limits
+func (c *Client) SyncRateLimits(ctx context.Context) error {
+	info, err := c.GetExchangeInfoWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	c.SetRateLimits(info.RateLimits)
+	return nil
+}
+
+func (c *Client) RateLimitUsage() RateLimitUsage {
+	c.limiter.mux.Lock()
+	defer c.limiter.mux.Unlock()
+	now := time.Now()
+	c.limiter.weight.roll(now)
+	c.limiter.orders10s.roll(now)
+	c.limiter.orders1m.roll(now)
+	c.limiter.data5m.roll(now)
+	return RateLimitUsage{
+		UsedWeight1m:  c.limiter.weight.used,
+		WeightLimit1m: c.limiter.weight.limit,
+		OrderCount10s: c.limiter.orders10s.used,
+		OrderLimit10s: c.limiter.orders10s.limit,
+		OrderCount1m:  c.limiter.orders1m.used,
+		OrderLimit1m:  c.limiter.orders1m.limit,
+		DataCount5m:   c.limiter.data5m.used,
+		DataLimit5m:   c.limiter.data5m.limit,
+		BannedUntil:   c.limiter.bannedUntil,
+	}
+}
+
+func (r RateLimit) duration() time.Duration {
+	var unit time.Duration
+	switch r.Interval {
+	case "SECOND":
+		unit = time.Second
+	case "MINUTE":
+		unit = time.Minute
+	case "HOUR":
+		unit = time.Hour
+	case "DAY":
+		unit = 24 * time.Hour
+	}
+	return unit * time.Duration(r.IntervalNum)
+}
+
+// internal funcs ------------------------------------------------
+
+func (l *rateLimiter) acquire(ctx context.Context, weight, orders, data int) error {
+	for {
+		l.mux.Lock()
+		wait, err := l.reserve(time.Now(), weight, orders, data)
+		mode := l.mode
+		l.mux.Unlock()
+		if err != nil {
+			return err
+		}
+		if wait <= 0 {
+			return nil
+		}
+		if mode == RateLimitFailFast {
+			return fmt.Errorf("%w, retry after %s", ErrRateLimited, wait)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve the usage and return 0, or return how long to wait.
+// a request over a whole limit would wait forever, it fails instead.
+func (l *rateLimiter) reserve(now time.Time, weight, orders, data int) (time.Duration, error) {
+	for _, c := range []struct {
+		w *usageWindow
+		n int
+	}{{&l.weight, weight}, {&l.orders10s, orders}, {&l.orders1m, orders}, {&l.data5m, data}} {
+		if c.w.limit > 0 && c.n > c.w.limit {
+			return 0, fmt.Errorf("%w: %d over the limit of %d per %s", ErrRateLimited, c.n, c.w.limit, c.w.interval)
+		}
+	}
+	if now.Before(l.bannedUntil) {
+		return l.bannedUntil.Sub(now), nil
+	}
+	var wait time.Duration
+	check := func(w *usageWindow, n int) {
+		w.roll(now)
+		if n == 0 || w.fits(n, l.threshold) {
+			return
+		}
+		if left := w.start.Add(w.interval).Sub(now); left > wait {
+			wait = left
+		}
+	}
+	check(&l.weight, weight)
+	check(&l.orders10s, orders)
+	check(&l.orders1m, orders)
+	check(&l.data5m, data)
+	if wait > 0 {
+		return wait, nil
+	}
+	l.weight.used += weight
+	l.orders10s.used += orders
+	l.orders1m.used += orders
+	l.data5m.used += data
+	return 0, nil
+}
+
+// the server reports the usage including the current request
+func (l *rateLimiter) update(header http.Header, statusCode int) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	now := time.Now()
+	report := func(w *usageWindow, key string) {
+		raw := header.Get(key)
+		if raw == "" {
+			return
+		}
+		used, err := strconv.Atoi(raw)
+		if err != nil {
+			return
+		}
+		w.roll(now)
+		if used > w.used {
+			w.used = used
+		}
+	}
+	report(&l.weight, "X-MBX-USED-WEIGHT-1M")
+	report(&l.orders10s, "X-MBX-ORDER-COUNT-10S")
+	report(&l.orders1m, "X-MBX-ORDER-COUNT-1M")
+	if statusCode != http.StatusTooManyRequests && statusCode != http.StatusTeapot {
+		return
+	}
+	until := now.Truncate(time.Minute).Add(time.Minute)
+	if sec, err := strconv.Atoi(header.Get("Retry-After")); err == nil && sec > 0 {
+		until = now.Add(time.Duration(sec) * time.Second)
+	}
+	if until.After(l.bannedUntil) {
+		l.bannedUntil = until
+	}
+}
+
+// windows are aligned to the wall clock like the server does
+func (w *usageWindow) roll(now time.Time) {
+	start := now.Truncate(w.interval)
+	if start.After(w.start) {
+		w.start = start
+		w.used = 0
+	}
+}
+
+// a request over the threshold may still go alone in a fresh window
+func (w *usageWindow) fits(n int, threshold float64) bool {
+	if w.limit <= 0 {
+		return true
+	}
+	if w.used == 0 {
+		return n <= w.limit
+	}
+	return float64(w.used+n) <= float64(w.limit)*threshold
+}
+
+// returns the ip weight and the order count of one request
+func endpointWeight(method, path string, values url.Values) (weight, orders int) {
+	hasSymbol := values.Get("symbol") != ""
+	switch path {
+	case "fapi/v1/depth":
+		limit, _ := strconv.Atoi(values.Get("limit"))
+		switch {
+		case limit <= 50:
+			return 2, 0
+		case limit <= 100:
+			return 5, 0
+		case limit <= 500:
+			return 10, 0
+		default:
+			return 20, 0
+		}
+	case "fapi/v1/klines", "fapi/v1/continuousKlines", "fapi/v1/indexPriceKlines", "fapi/v1/markPriceKlines":
+		limit, _ := strconv.Atoi(values.Get("limit"))
+		switch {
+		case limit < 100:
+			return 1, 0
+		case limit < 500:
+			return 2, 0
+		case limit <= 1000:
+			return 5, 0
+		default:
+			return 10, 0
+		}
+	case "fapi/v1/order":
+		if method == http.MethodPost {
+			return 1, 1
+		}
+		return 1, 0
+	case "fapi/v1/batchOrders":
+		if method == http.MethodPost {
+			return 5, 5
+		}
+		return 1, 0
+	case "fapi/v1/openOrders":
+		if !hasSymbol {
+			return 40, 0
+		}
+		return 1, 0
+	case "fapi/v1/ticker/24hr":
+		if !hasSymbol {
+			return 40, 0
+		}
+		return 1, 0
+	case "fapi/v1/ticker/price":
+		if !hasSymbol {
+			return 2, 0
+		}
+		return 1, 0
+	case "fapi/v1/ticker/bookTicker":
+		if !hasSymbol {
+			return 5, 0
+		}
+		return 2, 0
+	case "fapi/v1/premiumIndex":
+		if !hasSymbol {
+			return 10, 0
+		}
+		return 1, 0
+	case "fapi/v1/trades":
+		return 5, 0
+	case "fapi/v1/historicalTrades", "fapi/v1/aggTrades":
+		return 20, 0
+	case "fapi/v2/account", "fapi/v2/balance", "fapi/v2/positionRisk", "fapi/v1/allOrders", "fapi/v1/userTrades":
+		return 5, 0
+	case "fapi/v1/income":
+		return 30, 0
+	case "fapi/v1/positionSide/dual":
+		if method == http.MethodGet {
+			return 30, 0
+		}
+		return 1, 0
+	case "fapi/v1/commissionRate":
+		return 20, 0
+	case "fapi/v1/countdownCancelAll":
+		return 10, 0
+	}
+	if strings.HasPrefix(path, "fapi/") || strings.HasPrefix(path, "futures/data/") {
+		return 1, 0
+	}
+	return 0, 0
+}
+
+// futures/data requests count against their own 5 minute limit as well
+func dataRequests(path string) int {
+	if strings.HasPrefix(path, "futures/data/") {
+		return 1
+	}
+	return 0
+}
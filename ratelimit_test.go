@@ -0,0 +1,52 @@
+package appolloxapi
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestReserveHeavierThanLimit(t *testing.T) {
+	l := newRateLimiter()
+	l.weight.limit = 10
+	if _, err := l.reserve(time.Now(), 11, 0, 0); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("want ErrRateLimited, got %v", err)
+	}
+	// over the threshold but within the limit goes alone in a fresh window
+	if wait, err := l.reserve(time.Now(), 10, 0, 0); err != nil || wait != 0 {
+		t.Fatalf("want a reservation, got %s %v", wait, err)
+	}
+	if wait, _ := l.reserve(time.Now(), 1, 0, 0); wait <= 0 {
+		t.Fatal("want a wait once the window is used up")
+	}
+}
+
+func TestReserveDataRequests(t *testing.T) {
+	l := newRateLimiter()
+	l.data5m.limit = 10
+	now := time.Now()
+	// 9 within the 0.9 threshold
+	for i := 0; i < 9; i++ {
+		if wait, err := l.reserve(now, 1, 0, 1); err != nil || wait != 0 {
+			t.Fatalf("request %d: %s %v", i, wait, err)
+		}
+	}
+	if wait, _ := l.reserve(now, 1, 0, 1); wait <= 0 {
+		t.Fatal("want a wait on the futures/data limit")
+	}
+	// other endpoints are not held up by it
+	if wait, _ := l.reserve(now, 1, 0, 0); wait != 0 {
+		t.Fatalf("fapi request waits %s", wait)
+	}
+}
+
+func TestEndpointWeightFuturesData(t *testing.T) {
+	weight, _ := endpointWeight("GET", "futures/data/openInterestHist", url.Values{})
+	if weight == 0 || dataRequests("futures/data/openInterestHist") != 1 {
+		t.Fatal("futures/data requests are not counted")
+	}
+	if dataRequests("fapi/v1/depth") != 0 {
+		t.Fatal("fapi request counted as futures/data")
+	}
+}
@@ -0,0 +1,145 @@
+package appolloxapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	mrand "math/rand"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type RetryPolicy struct {
+	// including the first try, 1 or less means no retry
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// retries are off unless a policy is given, this is a sane one
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   200 * time.Millisecond,
+	MaxDelay:    3 * time.Second,
+}
+
+// GETs are retried on 5xx, timeouts and network errors.
+// order POSTs are only resent after the exchange confirms it does not know the client order id.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+var jitter = struct {
+	sync.Mutex
+	*mrand.Rand
+}{Rand: mrand.New(mrand.NewSource(time.Now().UnixNano()))}
+
+// internal funcs ------------------------------------------------
+
+func (p RetryPolicy) attempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// exponential backoff with jitter, between half and the full delay
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	if delay <= 0 {
+		delay = DefaultRetryPolicy.BaseDelay
+	}
+	for i := 0; i < attempt; i++ {
+		delay *= 2
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			delay = p.MaxDelay
+			break
+		}
+	}
+	half := int64(delay / 2)
+	jitter.Lock()
+	defer jitter.Unlock()
+	return time.Duration(half + jitter.Int63n(half+1))
+}
+
+func (p RetryPolicy) wait(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(p.backoff(attempt))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// true when the request may not have reached the matching engine, or may have
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError ||
+			apiErr.Code == CodeDisconnected ||
+			apiErr.Code == CodeTimeout
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// send a new order, after an ambiguous failure check the client order id before sending it again
+func (b *Client) submitOrder(ctx context.Context, symbol, clientID string, opts interface{}) (*OrderResponse, error) {
+	attempts := b.retry.attempts()
+	for attempt := 0; ; attempt++ {
+		res, err := b.do(ctx, http.MethodPost, "fapi/v1/order", opts, true, false)
+		if err == nil {
+			resp := &OrderResponse{}
+			err = json.Unmarshal(res, resp)
+			if err != nil {
+				return nil, err
+			}
+			return resp, nil
+		}
+		if attempt > 0 && errors.Is(err, ErrDuplicateClientOrderID) {
+			// an earlier try went through after all
+			order, lookupErr := b.QueryOrderByClientIDWithContext(ctx, symbol, clientID)
+			if lookupErr != nil {
+				return nil, fmt.Errorf("order %s in unknown state: %w", clientID, err)
+			}
+			return order.orderResponse(), nil
+		}
+		if !isRetryable(ctx, err) {
+			return nil, err
+		}
+		// ask the exchange even on the last attempt
+		if waitErr := b.retry.wait(ctx, attempt); waitErr != nil {
+			return nil, fmt.Errorf("order %s in unknown state: %w", clientID, err)
+		}
+		order, lookupErr := b.QueryOrderByClientIDWithContext(ctx, symbol, clientID)
+		if lookupErr == nil {
+			return order.orderResponse(), nil
+		}
+		if !errors.Is(lookupErr, ErrNoSuchOrder) {
+			return nil, fmt.Errorf("order %s in unknown state: %w", clientID, err)
+		}
+		// not placed for sure
+		if attempt+1 >= attempts {
+			return nil, err
+		}
+	}
+}
+
+func newClientOrderID() string {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("apx%d", time.Now().UnixNano())
+	}
+	return "apx" + hex.EncodeToString(buf)
+}
@@ -0,0 +1,138 @@
+package appolloxapi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type orderReply struct {
+	status int
+	body   string
+}
+
+func TestSubmitOrderAmbiguousFailure(t *testing.T) {
+	const (
+		unavailable = `{"code":-1000,"msg":"service unavailable"}`
+		noSuchOrder = `{"code":-2013,"msg":"Order does not exist."}`
+		placed      = `{"orderId":42,"clientOrderId":"cid","symbol":"BTCUSDT","status":"NEW"}`
+		duplicate   = `{"code":-4116,"msg":"ClientOrderId is duplicated."}`
+	)
+	cases := []struct {
+		name        string
+		policy      RetryPolicy
+		posts       []orderReply
+		gets        []orderReply
+		wantPosts   int64
+		wantGets    int64
+		wantOrderID int64
+		wantUnknown bool
+		wantErr     bool
+	}{
+		{
+			name:        "no retry, found by client id",
+			posts:       []orderReply{{503, unavailable}},
+			gets:        []orderReply{{200, placed}},
+			wantPosts:   1,
+			wantGets:    1,
+			wantOrderID: 42,
+		},
+		{
+			name:      "no retry, not placed",
+			posts:     []orderReply{{503, unavailable}},
+			gets:      []orderReply{{400, noSuchOrder}},
+			wantPosts: 1,
+			wantGets:  1,
+			wantErr:   true,
+		},
+		{
+			name:        "no retry, lookup fails too",
+			posts:       []orderReply{{503, unavailable}},
+			gets:        []orderReply{{503, unavailable}},
+			wantPosts:   1,
+			wantGets:    1,
+			wantErr:     true,
+			wantUnknown: true,
+		},
+		{
+			name:        "resent after the exchange denies it",
+			policy:      RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+			posts:       []orderReply{{503, unavailable}, {200, placed}},
+			gets:        []orderReply{{400, noSuchOrder}},
+			wantPosts:   2,
+			wantGets:    1,
+			wantOrderID: 42,
+		},
+		{
+			name:        "resent and duplicated, the first try went through",
+			policy:      RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+			posts:       []orderReply{{503, unavailable}, {400, duplicate}},
+			gets:        []orderReply{{400, noSuchOrder}, {200, placed}},
+			wantPosts:   2,
+			wantGets:    2,
+			wantOrderID: 42,
+		},
+		{
+			name:      "duplicated on the first try, no lookup",
+			posts:     []orderReply{{400, duplicate}},
+			wantPosts: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "rejected, no lookup",
+			posts:     []orderReply{{400, `{"code":-2019,"msg":"Margin is insufficient."}`}},
+			wantPosts: 1,
+			wantErr:   true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var posts, gets int64
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !strings.HasSuffix(r.URL.Path, "/fapi/v1/order") {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				var reply orderReply
+				switch r.Method {
+				case http.MethodPost:
+					n := atomic.AddInt64(&posts, 1)
+					reply = tc.posts[n-1]
+				case http.MethodGet:
+					n := atomic.AddInt64(&gets, 1)
+					reply = tc.gets[n-1]
+				}
+				w.WriteHeader(reply.status)
+				w.Write([]byte(reply.body))
+			}))
+			defer srv.Close()
+			client := New("key", "secret", "", WithBaseURL(srv.URL), WithRetryPolicy(tc.policy))
+			resp, err := client.SubmitOrder(PlaceOrderOpts{Symbol: "BTCUSDT", Side: "BUY", Type: OrderTypeMarket, Qty: "1", ClientID: "cid"})
+			if atomic.LoadInt64(&posts) != tc.wantPosts || atomic.LoadInt64(&gets) != tc.wantGets {
+				t.Fatalf("posts %d gets %d, want %d %d", posts, gets, tc.wantPosts, tc.wantGets)
+			}
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("want an error")
+				}
+				if unknown := strings.Contains(err.Error(), "unknown state"); unknown != tc.wantUnknown {
+					t.Fatalf("unknown state %v, want %v: %s", unknown, tc.wantUnknown, err)
+				}
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) {
+					t.Fatalf("want the api error wrapped: %s", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.OrderID != tc.wantOrderID {
+				t.Fatalf("order id %d, want %d", resp.OrderID, tc.wantOrderID)
+			}
+		})
+	}
+}
@@ -0,0 +1,355 @@
+package appolloxapi
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// the statistics only go back 30 days, period is one of 5m, 15m, 30m, 1h, 2h, 4h, 6h, 12h, 1d
+const maxStatsLimit = 500
+
+type OpenInterest struct {
+	Symbol       string          `json:"symbol"`
+	OpenInterest decimal.Decimal `json:"openInterest"`
+	Time         int64           `json:"time"`
+}
+
+type OpenInterestStat struct {
+	Symbol               string
+	SumOpenInterest      decimal.Decimal
+	SumOpenInterestValue decimal.Decimal
+	Timestamp            int64
+}
+
+// top trader account, top trader position and global account ratios share the model
+type LongShortRatio struct {
+	Symbol         string
+	LongShortRatio decimal.Decimal
+	LongAccount    decimal.Decimal
+	ShortAccount   decimal.Decimal
+	Timestamp      int64
+}
+
+type TakerVolume struct {
+	BuySellRatio decimal.Decimal
+	BuyVol       decimal.Decimal
+	SellVol      decimal.Decimal
+	Timestamp    int64
+}
+
+type OpenInterestHistory []OpenInterestStat
+
+type LongShortRatios []LongShortRatio
+
+type TakerVolumes []TakerVolume
+
+type StatsOpts struct {
+	Symbol    string `url:"symbol,omitempty"`
+	Period    string `url:"period"`
+	Limit     int    `url:"limit,omitempty"`
+	StartTime int64  `url:"startTime,omitempty"`
+	EndTime   int64  `url:"endTime,omitempty"`
+}
+
+func (b *Client) OpenInterest(symbol string) (*OpenInterest, error) {
+	return b.OpenInterestWithContext(context.Background(), symbol)
+}
+
+func (b *Client) OpenInterestWithContext(ctx context.Context, symbol string) (*OpenInterest, error) {
+	opts := onlySymbolOpts{
+		Symbol: strings.ToUpper(symbol),
+	}
+	res, err := b.do(ctx, http.MethodGet, "fapi/v1/openInterest", opts, false, false)
+	if err != nil {
+		return nil, err
+	}
+	resp := &OpenInterest{}
+	err = json.Unmarshal(res, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// default limit is 30, max is 500
+func (b *Client) OpenInterestHist(symbol string, period KlineInterval, limit int, start, end int64) (OpenInterestHistory, error) {
+	return b.OpenInterestHistWithContext(context.Background(), symbol, period, limit, start, end)
+}
+
+func (b *Client) OpenInterestHistWithContext(ctx context.Context, symbol string, period KlineInterval, limit int, start, end int64) (OpenInterestHistory, error) {
+	rows, err := b.stats(ctx, "futures/data/openInterestHist", symbol, period, limit, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return rows.openInterest(), nil
+}
+
+// every period within [start, end] in ms
+func (b *Client) OpenInterestHistRange(symbol string, period KlineInterval, start, end int64) (OpenInterestHistory, error) {
+	return b.OpenInterestHistRangeWithContext(context.Background(), symbol, period, start, end)
+}
+
+func (b *Client) OpenInterestHistRangeWithContext(ctx context.Context, symbol string, period KlineInterval, start, end int64) (OpenInterestHistory, error) {
+	rows, err := b.statsRange(ctx, "futures/data/openInterestHist", symbol, period, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return rows.openInterest(), nil
+}
+
+func (b *Client) TopLongShortAccountRatio(symbol string, period KlineInterval, limit int, start, end int64) (LongShortRatios, error) {
+	return b.TopLongShortAccountRatioWithContext(context.Background(), symbol, period, limit, start, end)
+}
+
+func (b *Client) TopLongShortAccountRatioWithContext(ctx context.Context, symbol string, period KlineInterval, limit int, start, end int64) (LongShortRatios, error) {
+	rows, err := b.stats(ctx, "futures/data/topLongShortAccountRatio", symbol, period, limit, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return rows.longShortRatio(), nil
+}
+
+func (b *Client) TopLongShortAccountRatioRange(symbol string, period KlineInterval, start, end int64) (LongShortRatios, error) {
+	return b.TopLongShortAccountRatioRangeWithContext(context.Background(), symbol, period, start, end)
+}
+
+func (b *Client) TopLongShortAccountRatioRangeWithContext(ctx context.Context, symbol string, period KlineInterval, start, end int64) (LongShortRatios, error) {
+	rows, err := b.statsRange(ctx, "futures/data/topLongShortAccountRatio", symbol, period, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return rows.longShortRatio(), nil
+}
+
+func (b *Client) TopLongShortPositionRatio(symbol string, period KlineInterval, limit int, start, end int64) (LongShortRatios, error) {
+	return b.TopLongShortPositionRatioWithContext(context.Background(), symbol, period, limit, start, end)
+}
+
+func (b *Client) TopLongShortPositionRatioWithContext(ctx context.Context, symbol string, period KlineInterval, limit int, start, end int64) (LongShortRatios, error) {
+	rows, err := b.stats(ctx, "futures/data/topLongShortPositionRatio", symbol, period, limit, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return rows.longShortRatio(), nil
+}
+
+func (b *Client) TopLongShortPositionRatioRange(symbol string, period KlineInterval, start, end int64) (LongShortRatios, error) {
+	return b.TopLongShortPositionRatioRangeWithContext(context.Background(), symbol, period, start, end)
+}
+
+func (b *Client) TopLongShortPositionRatioRangeWithContext(ctx context.Context, symbol string, period KlineInterval, start, end int64) (LongShortRatios, error) {
+	rows, err := b.statsRange(ctx, "futures/data/topLongShortPositionRatio", symbol, period, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return rows.longShortRatio(), nil
+}
+
+func (b *Client) GlobalLongShortAccountRatio(symbol string, period KlineInterval, limit int, start, end int64) (LongShortRatios, error) {
+	return b.GlobalLongShortAccountRatioWithContext(context.Background(), symbol, period, limit, start, end)
+}
+
+func (b *Client) GlobalLongShortAccountRatioWithContext(ctx context.Context, symbol string, period KlineInterval, limit int, start, end int64) (LongShortRatios, error) {
+	rows, err := b.stats(ctx, "futures/data/globalLongShortAccountRatio", symbol, period, limit, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return rows.longShortRatio(), nil
+}
+
+func (b *Client) GlobalLongShortAccountRatioRange(symbol string, period KlineInterval, start, end int64) (LongShortRatios, error) {
+	return b.GlobalLongShortAccountRatioRangeWithContext(context.Background(), symbol, period, start, end)
+}
+
+func (b *Client) GlobalLongShortAccountRatioRangeWithContext(ctx context.Context, symbol string, period KlineInterval, start, end int64) (LongShortRatios, error) {
+	rows, err := b.statsRange(ctx, "futures/data/globalLongShortAccountRatio", symbol, period, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return rows.longShortRatio(), nil
+}
+
+func (b *Client) TakerBuySellVolume(symbol string, period KlineInterval, limit int, start, end int64) (TakerVolumes, error) {
+	return b.TakerBuySellVolumeWithContext(context.Background(), symbol, period, limit, start, end)
+}
+
+func (b *Client) TakerBuySellVolumeWithContext(ctx context.Context, symbol string, period KlineInterval, limit int, start, end int64) (TakerVolumes, error) {
+	rows, err := b.stats(ctx, "futures/data/takerlongshortRatio", symbol, period, limit, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return rows.takerVolume(), nil
+}
+
+func (b *Client) TakerBuySellVolumeRange(symbol string, period KlineInterval, start, end int64) (TakerVolumes, error) {
+	return b.TakerBuySellVolumeRangeWithContext(context.Background(), symbol, period, start, end)
+}
+
+func (b *Client) TakerBuySellVolumeRangeWithContext(ctx context.Context, symbol string, period KlineInterval, start, end int64) (TakerVolumes, error) {
+	rows, err := b.statsRange(ctx, "futures/data/takerlongshortRatio", symbol, period, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return rows.takerVolume(), nil
+}
+
+// csv with a header row, timestamps in ms
+func (h OpenInterestHistory) WriteCSV(w io.Writer) error {
+	header := []string{"timestamp", "symbol", "sumOpenInterest", "sumOpenInterestValue"}
+	return writeCSV(w, header, len(h), func(i int) []string {
+		s := h[i]
+		return []string{strconv.FormatInt(s.Timestamp, 10), s.Symbol, s.SumOpenInterest.String(), s.SumOpenInterestValue.String()}
+	})
+}
+
+func (r LongShortRatios) WriteCSV(w io.Writer) error {
+	header := []string{"timestamp", "symbol", "longShortRatio", "longAccount", "shortAccount"}
+	return writeCSV(w, header, len(r), func(i int) []string {
+		s := r[i]
+		return []string{strconv.FormatInt(s.Timestamp, 10), s.Symbol, s.LongShortRatio.String(), s.LongAccount.String(), s.ShortAccount.String()}
+	})
+}
+
+func (t TakerVolumes) WriteCSV(w io.Writer) error {
+	header := []string{"timestamp", "buySellRatio", "buyVol", "sellVol"}
+	return writeCSV(w, header, len(t), func(i int) []string {
+		s := t[i]
+		return []string{strconv.FormatInt(s.Timestamp, 10), s.BuySellRatio.String(), s.BuyVol.String(), s.SellVol.String()}
+	})
+}
+
+// internal funcs ------------------------------------------------
+
+// union of the statistics fields, numbers come quoted or not depending on the endpoint
+type statsRow struct {
+	Symbol               string          `json:"symbol"`
+	SumOpenInterest      decimal.Decimal `json:"sumOpenInterest"`
+	SumOpenInterestValue decimal.Decimal `json:"sumOpenInterestValue"`
+	LongShortRatio       decimal.Decimal `json:"longShortRatio"`
+	LongAccount          decimal.Decimal `json:"longAccount"`
+	ShortAccount         decimal.Decimal `json:"shortAccount"`
+	BuySellRatio         decimal.Decimal `json:"buySellRatio"`
+	BuyVol               decimal.Decimal `json:"buyVol"`
+	SellVol              decimal.Decimal `json:"sellVol"`
+	Timestamp            decimal.Decimal `json:"timestamp"`
+}
+
+type statsRows []statsRow
+
+func (b *Client) stats(ctx context.Context, path, symbol string, period KlineInterval, limit int, start, end int64) (statsRows, error) {
+	opts := StatsOpts{
+		Symbol:    strings.ToUpper(symbol),
+		Period:    string(period),
+		Limit:     limit,
+		StartTime: start,
+		EndTime:   end,
+	}
+	if opts.Limit > maxStatsLimit {
+		opts.Limit = maxStatsLimit
+	}
+	res, err := b.do(ctx, http.MethodGet, path, opts, false, false)
+	if err != nil {
+		return nil, err
+	}
+	rows := statsRows{}
+	err = json.Unmarshal(res, &rows)
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (b *Client) statsRange(ctx context.Context, path, symbol string, period KlineInterval, start, end int64) (statsRows, error) {
+	if end < start {
+		return nil, errors.New("end should not be before start")
+	}
+	step, err := period.Duration()
+	if err != nil {
+		return nil, err
+	}
+	if now := time.Now().UnixNano() / int64(time.Millisecond); end > now {
+		end = now
+	}
+	result := statsRows{}
+	from := start
+	for from <= end {
+		to := from + int64(maxStatsLimit)*int64(step/time.Millisecond) - 1
+		if to > end {
+			to = end
+		}
+		page, err := b.stats(ctx, path, symbol, period, maxStatsLimit, from, to)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range page {
+			if len(result) != 0 && !row.Timestamp.GreaterThan(result[len(result)-1].Timestamp) {
+				continue
+			}
+			result = append(result, row)
+		}
+		from = to + 1
+	}
+	return result, nil
+}
+
+func (rows statsRows) openInterest() OpenInterestHistory {
+	list := make(OpenInterestHistory, 0, len(rows))
+	for _, row := range rows {
+		list = append(list, OpenInterestStat{
+			Symbol:               row.Symbol,
+			SumOpenInterest:      row.SumOpenInterest,
+			SumOpenInterestValue: row.SumOpenInterestValue,
+			Timestamp:            row.Timestamp.IntPart(),
+		})
+	}
+	return list
+}
+
+func (rows statsRows) longShortRatio() LongShortRatios {
+	list := make(LongShortRatios, 0, len(rows))
+	for _, row := range rows {
+		list = append(list, LongShortRatio{
+			Symbol:         row.Symbol,
+			LongShortRatio: row.LongShortRatio,
+			LongAccount:    row.LongAccount,
+			ShortAccount:   row.ShortAccount,
+			Timestamp:      row.Timestamp.IntPart(),
+		})
+	}
+	return list
+}
+
+func (rows statsRows) takerVolume() TakerVolumes {
+	list := make(TakerVolumes, 0, len(rows))
+	for _, row := range rows {
+		list = append(list, TakerVolume{
+			BuySellRatio: row.BuySellRatio,
+			BuyVol:       row.BuyVol,
+			SellVol:      row.SellVol,
+			Timestamp:    row.Timestamp.IntPart(),
+		})
+	}
+	return list
+}
+
+func writeCSV(w io.Writer, header []string, n int, row func(i int) []string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if err := writer.Write(row(i)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,331 @@
+package appolloxapi
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// what a subscriber does when it falls behind
+type DeliveryPolicy int
+
+const (
+	// the stream waits for the subscriber once Buffer items are pending,
+	// a stuck subscriber stalls every other consumer of the stream
+	DeliverBlock DeliveryPolicy = iota
+	// keep the newest Buffer items, the dropped ones are counted
+	DeliverDropOldest
+	// never wait and never drop, pending items grow without a limit
+	DeliverUnbounded
+)
+
+const defaultSubscriptionBuffer = 100
+
+const (
+	TopicTrades    = "trades"
+	TopicOrders    = "orders"
+	TopicBalances  = "balances"
+	TopicPositions = "positions"
+	TopicConfig    = "config"
+)
+
+// Buffer is ignored by DeliverUnbounded, default is 100
+type SubscribeOpts struct {
+	Policy DeliveryPolicy
+	Buffer int
+}
+
+type SubscriptionStats struct {
+	ID        int64
+	Topic     string
+	Policy    DeliveryPolicy
+	Pending   int
+	Delivered uint64
+	Dropped   uint64
+}
+
+// one consumer of a topic with its own queue, see the Subscribe and On calls of UserDataBranch
+type Subscription struct {
+	// first for the 64 bit alignment of atomic
+	delivered uint64
+	dropped   uint64
+	id        int64
+	topic     string
+	policy    DeliveryPolicy
+	buffer    int
+	mux       sync.Mutex
+	cond      *sync.Cond
+	queue     []interface{}
+	// no more items are taken
+	closed bool
+	// closed by Unsubscribe, ends a delivery waiting on the consumer
+	stop     chan struct{}
+	stopOnce sync.Once
+	hub      *subscriptionHub
+}
+
+type subscriptionHub struct {
+	mux    sync.RWMutex
+	nextID int64
+	subs   map[int64]*Subscription
+	// after closeAll, new subscriptions end right away
+	closed bool
+}
+
+// stop the delivery now, the pending items are dropped and counted.
+// when the branch closes instead, the pending items are delivered before the channel is closed.
+func (s *Subscription) Unsubscribe() {
+	// close first, wakes a publish blocked on this subscription
+	s.abandon()
+	s.hub.remove(s.id)
+}
+
+// items not delivered, by DeliverDropOldest or by Unsubscribe
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+func (s *Subscription) Stats() SubscriptionStats {
+	s.mux.Lock()
+	pending := len(s.queue)
+	s.mux.Unlock()
+	return SubscriptionStats{
+		ID:        s.id,
+		Topic:     s.topic,
+		Policy:    s.policy,
+		Pending:   pending,
+		Delivered: atomic.LoadUint64(&s.delivered),
+		Dropped:   atomic.LoadUint64(&s.dropped),
+	}
+}
+
+// every trade filled on the account
+func (u *UserDataBranch) SubscribeTrades(opts SubscribeOpts) (<-chan TradeData, *Subscription) {
+	ch := make(chan TradeData)
+	sub := u.subs.add(TopicTrades, opts, func(item interface{}, stop <-chan struct{}) bool {
+		select {
+		case ch <- item.(TradeData):
+			return true
+		case <-stop:
+			return false
+		}
+	}, func() { close(ch) })
+	return ch, sub
+}
+
+// same as SubscribeTrades with a handler instead of a channel
+func (u *UserDataBranch) OnTrade(opts SubscribeOpts, handler func(TradeData)) *Subscription {
+	return u.subs.add(TopicTrades, opts, func(item interface{}, stop <-chan struct{}) bool {
+		handler(item.(TradeData))
+		return true
+	}, nil)
+}
+
+// every change of an order, see OpenOrders for the current view
+func (u *UserDataBranch) SubscribeOrders(opts SubscribeOpts) (<-chan OrderState, *Subscription) {
+	ch := make(chan OrderState)
+	sub := u.subs.add(TopicOrders, opts, func(item interface{}, stop <-chan struct{}) bool {
+		select {
+		case ch <- item.(OrderState):
+			return true
+		case <-stop:
+			return false
+		}
+	}, func() { close(ch) })
+	return ch, sub
+}
+
+func (u *UserDataBranch) OnOrder(opts SubscribeOpts, handler func(OrderState)) *Subscription {
+	return u.subs.add(TopicOrders, opts, func(item interface{}, stop <-chan struct{}) bool {
+		handler(item.(OrderState))
+		return true
+	}, nil)
+}
+
+// balance changes of ACCOUNT_UPDATE
+func (u *UserDataBranch) SubscribeBalances(opts SubscribeOpts) (<-chan BalanceUpdate, *Subscription) {
+	ch := make(chan BalanceUpdate)
+	sub := u.subs.add(TopicBalances, opts, func(item interface{}, stop <-chan struct{}) bool {
+		select {
+		case ch <- item.(BalanceUpdate):
+			return true
+		case <-stop:
+			return false
+		}
+	}, func() { close(ch) })
+	return ch, sub
+}
+
+func (u *UserDataBranch) OnBalance(opts SubscribeOpts, handler func(BalanceUpdate)) *Subscription {
+	return u.subs.add(TopicBalances, opts, func(item interface{}, stop <-chan struct{}) bool {
+		handler(item.(BalanceUpdate))
+		return true
+	}, nil)
+}
+
+// position changes of ACCOUNT_UPDATE
+func (u *UserDataBranch) SubscribePositions(opts SubscribeOpts) (<-chan PositionUpdate, *Subscription) {
+	ch := make(chan PositionUpdate)
+	sub := u.subs.add(TopicPositions, opts, func(item interface{}, stop <-chan struct{}) bool {
+		select {
+		case ch <- item.(PositionUpdate):
+			return true
+		case <-stop:
+			return false
+		}
+	}, func() { close(ch) })
+	return ch, sub
+}
+
+func (u *UserDataBranch) OnPosition(opts SubscribeOpts, handler func(PositionUpdate)) *Subscription {
+	return u.subs.add(TopicPositions, opts, func(item interface{}, stop <-chan struct{}) bool {
+		handler(item.(PositionUpdate))
+		return true
+	}, nil)
+}
+
+// stats of every live subscription
+func (u *UserDataBranch) Subscriptions() []SubscriptionStats {
+	u.subs.mux.RLock()
+	defer u.subs.mux.RUnlock()
+	list := make([]SubscriptionStats, 0, len(u.subs.subs))
+	for _, sub := range u.subs.subs {
+		list = append(list, sub.Stats())
+	}
+	return list
+}
+
+// internal funcs ------------------------------------------------
+
+func (h *subscriptionHub) add(topic string, opts SubscribeOpts, deliver func(item interface{}, stop <-chan struct{}) bool, finish func()) *Subscription {
+	if opts.Buffer <= 0 {
+		opts.Buffer = defaultSubscriptionBuffer
+	}
+	sub := &Subscription{
+		topic:  topic,
+		policy: opts.Policy,
+		buffer: opts.Buffer,
+		stop:   make(chan struct{}),
+		hub:    h,
+	}
+	sub.cond = sync.NewCond(&sub.mux)
+	h.mux.Lock()
+	h.nextID++
+	sub.id = h.nextID
+	if h.closed {
+		// the pump ends at once and closes the channel
+		sub.closed = true
+	} else {
+		if h.subs == nil {
+			h.subs = make(map[int64]*Subscription)
+		}
+		h.subs[sub.id] = sub
+	}
+	h.mux.Unlock()
+	go sub.pump(deliver, finish)
+	return sub
+}
+
+func (h *subscriptionHub) remove(id int64) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	delete(h.subs, id)
+}
+
+func (h *subscriptionHub) publish(topic string, item interface{}) {
+	h.mux.RLock()
+	subs := make([]*Subscription, 0, len(h.subs))
+	for _, sub := range h.subs {
+		if sub.topic == topic {
+			subs = append(subs, sub)
+		}
+	}
+	h.mux.RUnlock()
+	for _, sub := range subs {
+		sub.push(item)
+	}
+}
+
+// the pending items are still delivered
+func (h *subscriptionHub) closeAll() {
+	h.mux.Lock()
+	subs := h.subs
+	h.subs = nil
+	h.closed = true
+	h.mux.Unlock()
+	for _, sub := range subs {
+		sub.close()
+	}
+}
+
+func (s *Subscription) push(item interface{}) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.closed {
+		return
+	}
+	switch s.policy {
+	case DeliverBlock:
+		for len(s.queue) >= s.buffer && !s.closed {
+			s.cond.Wait()
+		}
+		if s.closed {
+			return
+		}
+	case DeliverDropOldest:
+		if len(s.queue) >= s.buffer {
+			s.queue[0] = nil
+			s.queue = s.queue[1:]
+			atomic.AddUint64(&s.dropped, 1)
+		}
+	}
+	s.queue = append(s.queue, item)
+	s.cond.Broadcast()
+}
+
+func (s *Subscription) pump(deliver func(item interface{}, stop <-chan struct{}) bool, finish func()) {
+	if finish != nil {
+		defer finish()
+	}
+	for {
+		s.mux.Lock()
+		for len(s.queue) == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if len(s.queue) == 0 {
+			// closed and drained
+			s.mux.Unlock()
+			return
+		}
+		item := s.queue[0]
+		s.queue[0] = nil
+		s.queue = s.queue[1:]
+		s.cond.Broadcast()
+		s.mux.Unlock()
+		if deliver(item, s.stop) {
+			atomic.AddUint64(&s.delivered, 1)
+		} else {
+			atomic.AddUint64(&s.dropped, 1)
+		}
+	}
+}
+
+// take no more items, the pending ones are still delivered
+func (s *Subscription) close() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.closed = true
+	s.cond.Broadcast()
+}
+
+// take no more items and drop the pending ones
+func (s *Subscription) abandon() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.closed = true
+	atomic.AddUint64(&s.dropped, uint64(len(s.queue)))
+	s.queue = nil
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+	s.cond.Broadcast()
+}
@@ -0,0 +1,119 @@
+package appolloxapi
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func publishTrades(u *UserDataBranch, n int) {
+	for i := 0; i < n; i++ {
+		u.subs.publish(TopicTrades, TradeData{Oid: strconv.Itoa(i)})
+	}
+}
+
+func readTrades(t *testing.T, ch <-chan TradeData) []TradeData {
+	t.Helper()
+	list := []TradeData{}
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case trade, ok := <-ch:
+			if !ok {
+				return list
+			}
+			list = append(list, trade)
+		case <-timeout:
+			t.Fatal("channel not closed")
+		}
+	}
+}
+
+func TestSubscriptionDropOldest(t *testing.T) {
+	var u UserDataBranch
+	ch, sub := u.SubscribeTrades(SubscribeOpts{Policy: DeliverDropOldest, Buffer: 3})
+	publishTrades(&u, 10)
+	u.subs.closeAll()
+	list := readTrades(t, ch)
+	stats := sub.Stats()
+	if uint64(len(list))+stats.Dropped != 10 || stats.Delivered != uint64(len(list)) {
+		t.Fatalf("delivered %d dropped %d of 10", len(list), stats.Dropped)
+	}
+	// the newest are kept
+	if list[len(list)-1].Oid != "9" {
+		t.Fatalf("last trade %s, want 9", list[len(list)-1].Oid)
+	}
+}
+
+func TestSubscriptionBlock(t *testing.T) {
+	var u UserDataBranch
+	ch, sub := u.SubscribeTrades(SubscribeOpts{Policy: DeliverBlock, Buffer: 1})
+	go func() {
+		publishTrades(&u, 50)
+		u.subs.closeAll()
+	}()
+	list := readTrades(t, ch)
+	if len(list) != 50 || sub.Dropped() != 0 {
+		t.Fatalf("delivered %d dropped %d, want 50 and 0", len(list), sub.Dropped())
+	}
+	for i, trade := range list {
+		if trade.Oid != strconv.Itoa(i) {
+			t.Fatalf("trade %d is %s, out of order", i, trade.Oid)
+		}
+	}
+}
+
+func TestSubscriptionUnbounded(t *testing.T) {
+	var u UserDataBranch
+	ch, sub := u.SubscribeTrades(SubscribeOpts{Policy: DeliverUnbounded, Buffer: 1})
+	publishTrades(&u, 500)
+	u.subs.closeAll()
+	if list := readTrades(t, ch); len(list) != 500 || sub.Dropped() != 0 {
+		t.Fatalf("delivered %d dropped %d, want 500 and 0", len(list), sub.Dropped())
+	}
+}
+
+func TestSubscriptionUnsubscribeCountsPending(t *testing.T) {
+	var u UserDataBranch
+	ch, sub := u.SubscribeTrades(SubscribeOpts{Policy: DeliverUnbounded})
+	publishTrades(&u, 20)
+	sub.Unsubscribe()
+	list := readTrades(t, ch)
+	stats := sub.Stats()
+	if uint64(len(list))+stats.Dropped != 20 {
+		t.Fatalf("delivered %d dropped %d of 20", len(list), stats.Dropped)
+	}
+	if len(u.Subscriptions()) != 0 {
+		t.Fatal("subscription still listed")
+	}
+}
+
+func TestSubscriptionHandler(t *testing.T) {
+	var u UserDataBranch
+	got := make(chan TradeData, 5)
+	u.OnTrade(SubscribeOpts{}, func(trade TradeData) { got <- trade })
+	publishTrades(&u, 5)
+	for i := 0; i < 5; i++ {
+		select {
+		case trade := <-got:
+			if trade.Oid != strconv.Itoa(i) {
+				t.Fatalf("trade %d is %s", i, trade.Oid)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("handler not called")
+		}
+	}
+}
+
+func TestSubscribeAfterClose(t *testing.T) {
+	var u UserDataBranch
+	u.subs.closeAll()
+	ch, _ := u.SubscribeTrades(SubscribeOpts{})
+	publishTrades(&u, 3)
+	if list := readTrades(t, ch); len(list) != 0 {
+		t.Fatalf("got %d trades after close", len(list))
+	}
+	if len(u.Subscriptions()) != 0 {
+		t.Fatal("subscription listed after close")
+	}
+}
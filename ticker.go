@@ -0,0 +1,211 @@
+package appolloxapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+type Ticker24hr struct {
+	Symbol             string          `json:"symbol"`
+	PriceChange        decimal.Decimal `json:"priceChange"`
+	PriceChangePercent decimal.Decimal `json:"priceChangePercent"`
+	WeightedAvgPrice   decimal.Decimal `json:"weightedAvgPrice"`
+	LastPrice          decimal.Decimal `json:"lastPrice"`
+	LastQty            decimal.Decimal `json:"lastQty"`
+	OpenPrice          decimal.Decimal `json:"openPrice"`
+	HighPrice          decimal.Decimal `json:"highPrice"`
+	LowPrice           decimal.Decimal `json:"lowPrice"`
+	Volume             decimal.Decimal `json:"volume"`
+	QuoteVolume        decimal.Decimal `json:"quoteVolume"`
+	OpenTime           int64           `json:"openTime"`
+	CloseTime          int64           `json:"closeTime"`
+	FirstID            int64           `json:"firstId"`
+	LastID             int64           `json:"lastId"`
+	Count              int64           `json:"count"`
+}
+
+type TickerPrice struct {
+	Symbol string          `json:"symbol"`
+	Price  decimal.Decimal `json:"price"`
+	Time   int64           `json:"time"`
+}
+
+type BookTicker struct {
+	Symbol   string          `json:"symbol"`
+	BidPrice decimal.Decimal `json:"bidPrice"`
+	BidQty   decimal.Decimal `json:"bidQty"`
+	AskPrice decimal.Decimal `json:"askPrice"`
+	AskQty   decimal.Decimal `json:"askQty"`
+	Time     int64           `json:"time"`
+}
+
+// funding fields are zero for delivery contracts
+type PremiumIndex struct {
+	Symbol               string
+	MarkPrice            decimal.Decimal
+	IndexPrice           decimal.Decimal
+	EstimatedSettlePrice decimal.Decimal
+	LastFundingRate      decimal.Decimal
+	InterestRate         decimal.Decimal
+	NextFundingTime      int64
+	Time                 int64
+}
+
+type premiumIndexRaw struct {
+	Symbol               string `json:"symbol"`
+	MarkPrice            string `json:"markPrice"`
+	IndexPrice           string `json:"indexPrice"`
+	EstimatedSettlePrice string `json:"estimatedSettlePrice"`
+	LastFundingRate      string `json:"lastFundingRate"`
+	InterestRate         string `json:"interestRate"`
+	NextFundingTime      int64  `json:"nextFundingTime"`
+	Time                 int64  `json:"time"`
+}
+
+func (b *Client) Ticker24hr(symbol string) (*Ticker24hr, error) {
+	return b.Ticker24hrWithContext(context.Background(), symbol)
+}
+
+func (b *Client) Ticker24hrWithContext(ctx context.Context, symbol string) (*Ticker24hr, error) {
+	resp := &Ticker24hr{}
+	if err := b.ticker(ctx, "fapi/v1/ticker/24hr", symbol, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// all symbols, weight 40
+func (b *Client) Tickers24hr() ([]Ticker24hr, error) {
+	return b.Tickers24hrWithContext(context.Background())
+}
+
+func (b *Client) Tickers24hrWithContext(ctx context.Context) ([]Ticker24hr, error) {
+	resp := []Ticker24hr{}
+	if err := b.ticker(ctx, "fapi/v1/ticker/24hr", "", &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (b *Client) TickerPrice(symbol string) (*TickerPrice, error) {
+	return b.TickerPriceWithContext(context.Background(), symbol)
+}
+
+func (b *Client) TickerPriceWithContext(ctx context.Context, symbol string) (*TickerPrice, error) {
+	resp := &TickerPrice{}
+	if err := b.ticker(ctx, "fapi/v1/ticker/price", symbol, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (b *Client) TickerPrices() ([]TickerPrice, error) {
+	return b.TickerPricesWithContext(context.Background())
+}
+
+func (b *Client) TickerPricesWithContext(ctx context.Context) ([]TickerPrice, error) {
+	resp := []TickerPrice{}
+	if err := b.ticker(ctx, "fapi/v1/ticker/price", "", &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// best bid and ask without a depth request
+func (b *Client) BookTicker(symbol string) (*BookTicker, error) {
+	return b.BookTickerWithContext(context.Background(), symbol)
+}
+
+func (b *Client) BookTickerWithContext(ctx context.Context, symbol string) (*BookTicker, error) {
+	resp := &BookTicker{}
+	if err := b.ticker(ctx, "fapi/v1/ticker/bookTicker", symbol, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (b *Client) BookTickers() ([]BookTicker, error) {
+	return b.BookTickersWithContext(context.Background())
+}
+
+func (b *Client) BookTickersWithContext(ctx context.Context) ([]BookTicker, error) {
+	resp := []BookTicker{}
+	if err := b.ticker(ctx, "fapi/v1/ticker/bookTicker", "", &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// mark price, index price and funding of the symbol
+func (b *Client) PremiumIndex(symbol string) (*PremiumIndex, error) {
+	return b.PremiumIndexWithContext(context.Background(), symbol)
+}
+
+func (b *Client) PremiumIndexWithContext(ctx context.Context, symbol string) (*PremiumIndex, error) {
+	raw := premiumIndexRaw{}
+	if err := b.ticker(ctx, "fapi/v1/premiumIndex", symbol, &raw); err != nil {
+		return nil, err
+	}
+	return raw.premiumIndex()
+}
+
+func (b *Client) PremiumIndexes() ([]PremiumIndex, error) {
+	return b.PremiumIndexesWithContext(context.Background())
+}
+
+func (b *Client) PremiumIndexesWithContext(ctx context.Context) ([]PremiumIndex, error) {
+	raws := []premiumIndexRaw{}
+	if err := b.ticker(ctx, "fapi/v1/premiumIndex", "", &raws); err != nil {
+		return nil, err
+	}
+	resp := make([]PremiumIndex, 0, len(raws))
+	for _, raw := range raws {
+		index, err := raw.premiumIndex()
+		if err != nil {
+			return nil, err
+		}
+		resp = append(resp, *index)
+	}
+	return resp, nil
+}
+
+// internal funcs ------------------------------------------------
+
+type optionalSymbolOpts struct {
+	Symbol string `url:"symbol,omitempty"`
+}
+
+// empty symbol asks for every symbol
+func (b *Client) ticker(ctx context.Context, path, symbol string, out interface{}) error {
+	opts := optionalSymbolOpts{
+		Symbol: strings.ToUpper(symbol),
+	}
+	res, err := b.do(ctx, http.MethodGet, path, opts, false, false)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(res, out)
+}
+
+// delivery contracts send empty funding fields
+func (r *premiumIndexRaw) premiumIndex() (*PremiumIndex, error) {
+	index := &PremiumIndex{
+		Symbol:          r.Symbol,
+		NextFundingTime: r.NextFundingTime,
+		Time:            r.Time,
+	}
+	err := parseDecimals(
+		r.MarkPrice, &index.MarkPrice,
+		r.IndexPrice, &index.IndexPrice,
+		r.EstimatedSettlePrice, &index.EstimatedSettlePrice,
+		r.LastFundingRate, &index.LastFundingRate,
+		r.InterestRate, &index.InterestRate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return index, nil
+}
@@ -0,0 +1,99 @@
+package appolloxapi
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// samples taken per sync, the one with the lowest rtt wins
+const timeSyncSamples = 3
+
+type clockSync struct {
+	// server time minus local time, in nanoseconds
+	offset  int64
+	enabled int32
+	mux     sync.Mutex
+	// guards the StartTimeSync loop, only one runs at a time
+	loop    sync.Mutex
+	running bool
+}
+
+// measure the offset against fapi/v1/time once and apply it to every signed timestamp
+func (c *Client) SyncServerTime(ctx context.Context) error {
+	c.clock.mux.Lock()
+	defer c.clock.mux.Unlock()
+	var best, bestRTT time.Duration
+	for i := 0; i < timeSyncSamples; i++ {
+		sent := time.Now()
+		server, err := c.TimeWithContext(ctx)
+		if err != nil {
+			return err
+		}
+		received := time.Now()
+		rtt := received.Sub(sent)
+		// assume the server stamped the response half way through the round trip
+		offset := server.Sub(sent.Add(rtt / 2))
+		if i == 0 || rtt < bestRTT {
+			best, bestRTT = offset, rtt
+		}
+	}
+	atomic.StoreInt64(&c.clock.offset, int64(best))
+	return nil
+}
+
+// sync now, then keep syncing on the interval until ctx is done.
+// while running, a -1021 error triggers one resync and one retry of the request.
+// a call while a loop is running does nothing.
+func (c *Client) StartTimeSync(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return errors.New("time sync interval should be positive")
+	}
+	c.clock.loop.Lock()
+	defer c.clock.loop.Unlock()
+	if c.clock.running {
+		return nil
+	}
+	if err := c.SyncServerTime(ctx); err != nil {
+		return err
+	}
+	c.clock.running = true
+	atomic.StoreInt32(&c.clock.enabled, 1)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		defer func() {
+			c.clock.loop.Lock()
+			c.clock.running = false
+			atomic.StoreInt32(&c.clock.enabled, 0)
+			c.clock.loop.Unlock()
+		}()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// keep the last offset when it fails
+				c.SyncServerTime(ctx)
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *Client) ServerTimeOffset() time.Duration {
+	return time.Duration(atomic.LoadInt64(&c.clock.offset))
+}
+
+// internal funcs ------------------------------------------------
+
+// in milliseconds
+func (c *Client) timestamp() int64 {
+	return time.Now().Add(c.ServerTimeOffset()).UnixNano() / int64(time.Millisecond)
+}
+
+func (c *Client) timeSyncEnabled() bool {
+	return atomic.LoadInt32(&c.clock.enabled) == 1
+}
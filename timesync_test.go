@@ -0,0 +1,53 @@
+package appolloxapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStartTimeSyncOnce(t *testing.T) {
+	var calls int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&calls, 1)
+		fmt.Fprintf(w, `{"serverTime":%d}`, time.Now().UnixNano()/int64(time.Millisecond))
+	}))
+	defer srv.Close()
+	client := New("key", "secret", "", WithBaseURL(srv.URL))
+
+	first, stopFirst := context.WithCancel(context.Background())
+	if err := client.StartTimeSync(first, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	second, stopSecond := context.WithCancel(context.Background())
+	defer stopSecond()
+	if err := client.StartTimeSync(second, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt64(&calls); got != timeSyncSamples {
+		t.Fatalf("%d time requests, want one sync of %d", got, timeSyncSamples)
+	}
+	if !client.timeSyncEnabled() {
+		t.Fatal("time sync not enabled")
+	}
+
+	// stopping the running loop turns the sync off, then a new start works again
+	stopFirst()
+	deadline := time.Now().Add(time.Second)
+	for client.timeSyncEnabled() {
+		if time.Now().After(deadline) {
+			t.Fatal("time sync still enabled after its loop stopped")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := client.StartTimeSync(second, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if !client.timeSyncEnabled() {
+		t.Fatal("time sync not enabled after restart")
+	}
+}
@@ -0,0 +1,237 @@
+package appolloxapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	maxTradesLimit = 1000
+	// aggTrades refuses a startTime to endTime window of an hour or more
+	aggTradesMaxWindow = time.Hour
+)
+
+type Trade struct {
+	ID           int64           `json:"id"`
+	Price        decimal.Decimal `json:"price"`
+	Qty          decimal.Decimal `json:"qty"`
+	QuoteQty     decimal.Decimal `json:"quoteQty"`
+	Time         int64           `json:"time"`
+	IsBuyerMaker bool            `json:"isBuyerMaker"`
+}
+
+type AggTrade struct {
+	AggID        int64           `json:"a"`
+	Price        decimal.Decimal `json:"p"`
+	Qty          decimal.Decimal `json:"q"`
+	FirstTradeID int64           `json:"f"`
+	LastTradeID  int64           `json:"l"`
+	Time         int64           `json:"T"`
+	IsBuyerMaker bool            `json:"m"`
+}
+
+type TradesOpts struct {
+	Symbol string `url:"symbol"`
+	Limit  int    `url:"limit,omitempty"`
+	FromID *int64 `url:"fromId,omitempty"`
+}
+
+// without fromId and a time window, the most recent trades are returned
+type AggTradesOpts struct {
+	Symbol    string `url:"symbol"`
+	FromID    *int64 `url:"fromId,omitempty"`
+	StartTime int64  `url:"startTime,omitempty"`
+	EndTime   int64  `url:"endTime,omitempty"`
+	Limit     int    `url:"limit,omitempty"`
+}
+
+// recent trades, default limit is 500, max is 1000
+func (b *Client) Trades(symbol string, limit int) ([]Trade, error) {
+	return b.TradesWithContext(context.Background(), symbol, limit)
+}
+
+func (b *Client) TradesWithContext(ctx context.Context, symbol string, limit int) ([]Trade, error) {
+	opts := TradesOpts{
+		Symbol: strings.ToUpper(symbol),
+		Limit:  limit,
+	}
+	if opts.Limit > maxTradesLimit {
+		opts.Limit = maxTradesLimit
+	}
+	res, err := b.do(ctx, http.MethodGet, "fapi/v1/trades", opts, false, false)
+	if err != nil {
+		return nil, err
+	}
+	trades := []Trade{}
+	err = json.Unmarshal(res, &trades)
+	if err != nil {
+		return nil, err
+	}
+	return trades, nil
+}
+
+// older trades from fromID, nil means the most recent ones. needs the api key.
+func (b *Client) HistoricalTrades(symbol string, limit int, fromID *int64) ([]Trade, error) {
+	return b.HistoricalTradesWithContext(context.Background(), symbol, limit, fromID)
+}
+
+func (b *Client) HistoricalTradesWithContext(ctx context.Context, symbol string, limit int, fromID *int64) ([]Trade, error) {
+	opts := TradesOpts{
+		Symbol: strings.ToUpper(symbol),
+		Limit:  limit,
+		FromID: fromID,
+	}
+	if opts.Limit > maxTradesLimit {
+		opts.Limit = maxTradesLimit
+	}
+	res, err := b.do(ctx, http.MethodGet, "fapi/v1/historicalTrades", opts, false, true)
+	if err != nil {
+		return nil, err
+	}
+	trades := []Trade{}
+	err = json.Unmarshal(res, &trades)
+	if err != nil {
+		return nil, err
+	}
+	return trades, nil
+}
+
+// one page, see AggTradesFromID and AggTradesBetween to walk further
+func (b *Client) AggTrades(opts AggTradesOpts) ([]AggTrade, error) {
+	return b.AggTradesWithContext(context.Background(), opts)
+}
+
+func (b *Client) AggTradesWithContext(ctx context.Context, opts AggTradesOpts) ([]AggTrade, error) {
+	opts.Symbol = strings.ToUpper(opts.Symbol)
+	if opts.Limit == 0 || opts.Limit > maxTradesLimit {
+		opts.Limit = maxTradesLimit
+	}
+	res, err := b.do(ctx, http.MethodGet, "fapi/v1/aggTrades", opts, false, false)
+	if err != nil {
+		return nil, err
+	}
+	trades := []AggTrade{}
+	err = json.Unmarshal(res, &trades)
+	if err != nil {
+		return nil, err
+	}
+	return trades, nil
+}
+
+// usage:
+//
+//	it := client.AggTradesBetween("BTCUSDT", start, end)
+//	for it.Next(ctx) {
+//		trade := it.Trade()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type AggTradeIterator struct {
+	client  *Client
+	symbol  string
+	byID    bool
+	nextID  int64
+	cursor  int64
+	end     int64
+	buf     []AggTrade
+	current AggTrade
+	err     error
+	done    bool
+}
+
+// walk from the agg trade id up to the latest trade
+func (b *Client) AggTradesFromID(symbol string, fromID int64) *AggTradeIterator {
+	return &AggTradeIterator{
+		client: b,
+		symbol: strings.ToUpper(symbol),
+		byID:   true,
+		nextID: fromID,
+		end:    -1,
+	}
+}
+
+// walk the trades within [start, end] in ms, any length of window
+func (b *Client) AggTradesBetween(symbol string, start, end int64) *AggTradeIterator {
+	return &AggTradeIterator{
+		client: b,
+		symbol: strings.ToUpper(symbol),
+		cursor: start,
+		end:    end,
+	}
+}
+
+func (it *AggTradeIterator) Next(ctx context.Context) bool {
+	for len(it.buf) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		it.err = it.fetch(ctx)
+	}
+	it.current = it.buf[0]
+	it.buf = it.buf[1:]
+	if it.end >= 0 && it.current.Time > it.end {
+		it.done = true
+		it.buf = nil
+		return false
+	}
+	return true
+}
+
+func (it *AggTradeIterator) Trade() AggTrade {
+	return it.current
+}
+
+func (it *AggTradeIterator) Err() error {
+	return it.err
+}
+
+// internal funcs ------------------------------------------------
+
+// look for the first trade hour by hour, then follow the ids
+func (it *AggTradeIterator) fetch(ctx context.Context) error {
+	if !it.byID {
+		if it.cursor > it.end {
+			it.done = true
+			return nil
+		}
+		to := it.cursor + int64(aggTradesMaxWindow/time.Millisecond) - 1
+		if to > it.end {
+			to = it.end
+		}
+		trades, err := it.client.AggTradesWithContext(ctx, AggTradesOpts{
+			Symbol:    it.symbol,
+			StartTime: it.cursor,
+			EndTime:   to,
+		})
+		if err != nil {
+			return err
+		}
+		if len(trades) == 0 {
+			it.cursor = to + 1
+			return nil
+		}
+		it.buf = trades
+		it.byID = true
+		it.nextID = trades[len(trades)-1].AggID + 1
+		return nil
+	}
+	id := it.nextID
+	trades, err := it.client.AggTradesWithContext(ctx, AggTradesOpts{
+		Symbol: it.symbol,
+		FromID: &id,
+	})
+	if err != nil {
+		return err
+	}
+	if len(trades) == 0 {
+		it.done = true
+		return nil
+	}
+	it.buf = trades
+	it.nextID = trades[len(trades)-1].AggID + 1
+	return nil
+}
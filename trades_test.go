@@ -0,0 +1,39 @@
+package appolloxapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTradesFromIDZero(t *testing.T) {
+	ids := make(chan string, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.URL.Query()["fromId"]; ok {
+			ids <- r.URL.Path + " fromId=" + r.URL.Query().Get("fromId")
+		}
+		w.Write([]byte("[]"))
+	}))
+	defer srv.Close()
+	client := New("key", "secret", "", WithBaseURL(srv.URL))
+
+	trades := client.AggTradesFromID("btcusdt", 0)
+	if trades.Next(context.Background()) || trades.Err() != nil {
+		t.Fatalf("want an empty walk, got err %v", trades.Err())
+	}
+	first := int64(0)
+	if _, err := client.HistoricalTrades("btcusdt", 10, &first); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"/fapi/v1/aggTrades fromId=0", "/fapi/v1/historicalTrades fromId=0"} {
+		select {
+		case got := <-ids:
+			if got != want {
+				t.Fatalf("sent %s, want %s", got, want)
+			}
+		default:
+			t.Fatalf("%s not sent", want)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package appolloxapi
 
-import "net/http"
+import (
+	"context"
+	"net/http"
+)
 
 type PutListenKeyOpts struct {
 	ListenKey string `url:"listenKey"`
@@ -11,7 +14,11 @@ type ListenKeyResponse struct {
 }
 
 func (b *Client) GetListenKey() (*ListenKeyResponse, error) {
-	res, err := b.do(http.MethodPost, "fapi/v1/listenKey", nil, false, true)
+	return b.GetListenKeyWithContext(context.Background())
+}
+
+func (b *Client) GetListenKeyWithContext(ctx context.Context) (*ListenKeyResponse, error) {
+	res, err := b.do(ctx, http.MethodPost, "fapi/v1/listenKey", nil, false, true)
 	if err != nil {
 		return nil, err
 	}
@@ -24,10 +31,30 @@ func (b *Client) GetListenKey() (*ListenKeyResponse, error) {
 }
 
 func (b *Client) PutListenKey(listenKey string) error {
+	return b.PutListenKeyWithContext(context.Background(), listenKey)
+}
+
+func (b *Client) PutListenKeyWithContext(ctx context.Context, listenKey string) error {
+	opts := PutListenKeyOpts{
+		ListenKey: listenKey,
+	}
+	_, err := b.do(ctx, http.MethodPut, "fapi/v1/listenKey", opts, false, true)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// close the user data stream of the listen key
+func (b *Client) DeleteListenKey(listenKey string) error {
+	return b.DeleteListenKeyWithContext(context.Background(), listenKey)
+}
+
+func (b *Client) DeleteListenKeyWithContext(ctx context.Context, listenKey string) error {
 	opts := PutListenKeyOpts{
 		ListenKey: listenKey,
 	}
-	_, err := b.do(http.MethodPut, "fapi/v1/listenKey", opts, false, true)
+	_, err := b.do(ctx, http.MethodDelete, "fapi/v1/listenKey", opts, false, true)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,252 @@
+package appolloxapi
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/shopspring/decimal"
+)
+
+type UserDataEventType string
+
+const (
+	EventAccountUpdate       UserDataEventType = "ACCOUNT_UPDATE"
+	EventOrderTradeUpdate    UserDataEventType = "ORDER_TRADE_UPDATE"
+	EventMarginCall          UserDataEventType = "MARGIN_CALL"
+	EventAccountConfigUpdate UserDataEventType = "ACCOUNT_CONFIG_UPDATE"
+	EventListenKeyExpired    UserDataEventType = "listenKeyExpired"
+)
+
+// reason m of ACCOUNT_UPDATE
+const (
+	ReasonDeposit             = "DEPOSIT"
+	ReasonWithdraw            = "WITHDRAW"
+	ReasonOrder               = "ORDER"
+	ReasonFundingFee          = "FUNDING_FEE"
+	ReasonWithdrawReject      = "WITHDRAW_REJECT"
+	ReasonAdjustment          = "ADJUSTMENT"
+	ReasonInsuranceClear      = "INSURANCE_CLEAR"
+	ReasonAdminDeposit        = "ADMIN_DEPOSIT"
+	ReasonAdminWithdraw       = "ADMIN_WITHDRAW"
+	ReasonMarginTransfer      = "MARGIN_TRANSFER"
+	ReasonMarginTypeChange    = "MARGIN_TYPE_CHANGE"
+	ReasonAssetTransfer       = "ASSET_TRANSFER"
+	ReasonOptionsPremiumFee   = "OPTIONS_PREMIUM_FEE"
+	ReasonOptionsSettleProfit = "OPTIONS_SETTLE_PROFIT"
+	ReasonAutoExchange        = "AUTO_EXCHANGE"
+)
+
+var (
+	ErrUnknownEvent = errors.New("unknown user data event")
+	ErrInvalidEvent = errors.New("invalid user data event")
+)
+
+// every typed event below is one of these, switch on the concrete type:
+//
+//	switch e := event.(type) {
+//	case *OrderTradeUpdateEvent:
+//	case *AccountUpdateEvent:
+//	}
+type UserDataEvent interface {
+	EventType() UserDataEventType
+	EventTime() time.Time
+}
+
+type EventHeader struct {
+	Type UserDataEventType `json:"e"`
+	Time int64             `json:"E"`
+}
+
+func (h *EventHeader) EventType() UserDataEventType {
+	return h.Type
+}
+
+func (h *EventHeader) EventTime() time.Time {
+	return time.Unix(0, h.Time*int64(time.Millisecond))
+}
+
+type AccountUpdateEvent struct {
+	EventHeader
+	TransactionTime int64         `json:"T"`
+	Update          AccountUpdate `json:"a"`
+}
+
+type AccountUpdate struct {
+	Reason    string           `json:"m"`
+	Balances  []BalanceUpdate  `json:"B"`
+	Positions []PositionUpdate `json:"P"`
+}
+
+type BalanceUpdate struct {
+	Asset              string          `json:"a"`
+	WalletBalance      decimal.Decimal `json:"wb"`
+	CrossWalletBalance decimal.Decimal `json:"cw"`
+	BalanceChange      decimal.Decimal `json:"bc"`
+}
+
+type PositionUpdate struct {
+	Symbol              string          `json:"s"`
+	PositionAmt         decimal.Decimal `json:"pa"`
+	EntryPrice          decimal.Decimal `json:"ep"`
+	AccumulatedRealized decimal.Decimal `json:"cr"`
+	UnrealizedPnl       decimal.Decimal `json:"up"`
+	MarginType          string          `json:"mt"`
+	IsolatedWallet      decimal.Decimal `json:"iw"`
+	PositionSide        string          `json:"ps"`
+}
+
+type OrderTradeUpdateEvent struct {
+	EventHeader
+	TransactionTime int64       `json:"T"`
+	Order           OrderUpdate `json:"o"`
+}
+
+type OrderUpdate struct {
+	Symbol          string          `json:"s"`
+	ClientOrderID   string          `json:"c"`
+	Side            string          `json:"S"`
+	Type            string          `json:"o"`
+	TimeInForce     string          `json:"f"`
+	OrigQty         decimal.Decimal `json:"q"`
+	Price           decimal.Decimal `json:"p"`
+	AvgPrice        decimal.Decimal `json:"ap"`
+	StopPrice       decimal.Decimal `json:"sp"`
+	ExecType        string          `json:"x"`
+	Status          string          `json:"X"`
+	OrderID         int64           `json:"i"`
+	LastFilledQty   decimal.Decimal `json:"l"`
+	FilledQty       decimal.Decimal `json:"z"`
+	LastFilledPrice decimal.Decimal `json:"L"`
+	CommissionAsset string          `json:"N"`
+	Commission      decimal.Decimal `json:"n"`
+	TradeTime       int64           `json:"T"`
+	TradeID         int64           `json:"t"`
+	BidsNotional    decimal.Decimal `json:"b"`
+	AsksNotional    decimal.Decimal `json:"a"`
+	IsMaker         bool            `json:"m"`
+	ReduceOnly      bool            `json:"R"`
+	WorkingType     string          `json:"wt"`
+	OrigType        string          `json:"ot"`
+	PositionSide    string          `json:"ps"`
+	ClosePosition   bool            `json:"cp"`
+	ActivationPrice decimal.Decimal `json:"AP"`
+	CallbackRate    decimal.Decimal `json:"cr"`
+	RealizedProfit  decimal.Decimal `json:"rp"`
+}
+
+type MarginCallEvent struct {
+	EventHeader
+	CrossWalletBalance decimal.Decimal      `json:"cw"`
+	Positions          []MarginCallPosition `json:"p"`
+}
+
+type MarginCallPosition struct {
+	Symbol            string          `json:"s"`
+	PositionSide      string          `json:"ps"`
+	PositionAmt       decimal.Decimal `json:"pa"`
+	MarginType        string          `json:"mt"`
+	IsolatedWallet    decimal.Decimal `json:"iw"`
+	MarkPrice         decimal.Decimal `json:"mp"`
+	UnrealizedPnl     decimal.Decimal `json:"up"`
+	MaintenanceMargin decimal.Decimal `json:"mm"`
+}
+
+// one of Leverage or MultiAssets is set
+type AccountConfigUpdateEvent struct {
+	EventHeader
+	TransactionTime int64              `json:"T"`
+	Leverage        *LeverageConfig    `json:"ac"`
+	MultiAssets     *MultiAssetsConfig `json:"ai"`
+}
+
+type LeverageConfig struct {
+	Symbol   string `json:"s"`
+	Leverage int    `json:"l"`
+}
+
+type MultiAssetsConfig struct {
+	MultiAssetsMargin bool `json:"j"`
+}
+
+// the stream stops after this, a new listen key is needed
+type ListenKeyExpiredEvent struct {
+	EventHeader
+}
+
+// the frame carried an event type this package does not know, errors.Is(err, ErrUnknownEvent) is true
+type UnknownEventError struct {
+	Type string
+	Raw  []byte
+}
+
+func (e *UnknownEventError) Error() string {
+	return fmt.Sprintf("%s %q", ErrUnknownEvent, e.Type)
+}
+
+func (e *UnknownEventError) Is(target error) bool {
+	return target == ErrUnknownEvent
+}
+
+// the frame could not be decoded, errors.Is(err, ErrInvalidEvent) is true
+type InvalidEventError struct {
+	Type string
+	Raw  []byte
+	Err  error
+}
+
+func (e *InvalidEventError) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("%s: %s", ErrInvalidEvent, e.Err)
+	}
+	return fmt.Sprintf("%s %q: %s", ErrInvalidEvent, e.Type, e.Err)
+}
+
+func (e *InvalidEventError) Is(target error) bool {
+	return target == ErrInvalidEvent
+}
+
+func (e *InvalidEventError) Unwrap() error {
+	return e.Err
+}
+
+// decode one user data stream frame into its typed event
+func DecodeUserDataEvent(raw []byte) (UserDataEvent, error) {
+	header := EventHeader{}
+	if err := eventJSON.Unmarshal(raw, &header); err != nil {
+		return nil, &InvalidEventError{Raw: raw, Err: err}
+	}
+	if header.Type == "" {
+		return nil, &InvalidEventError{Raw: raw, Err: errors.New("missing event type")}
+	}
+	var event UserDataEvent
+	switch header.Type {
+	case EventAccountUpdate:
+		event = &AccountUpdateEvent{}
+	case EventOrderTradeUpdate:
+		event = &OrderTradeUpdateEvent{}
+	case EventMarginCall:
+		event = &MarginCallEvent{}
+	case EventAccountConfigUpdate:
+		event = &AccountConfigUpdateEvent{}
+	case EventListenKeyExpired:
+		event = &ListenKeyExpiredEvent{}
+	default:
+		return nil, &UnknownEventError{Type: string(header.Type), Raw: raw}
+	}
+	if err := eventJSON.Unmarshal(raw, event); err != nil {
+		return nil, &InvalidEventError{Type: string(header.Type), Raw: raw, Err: err}
+	}
+	return event, nil
+}
+
+// internal funcs ------------------------------------------------
+
+// the event keys differ only by case, like s and S, so they are matched exactly
+var eventJSON = jsoniter.Config{
+	EscapeHTML:             true,
+	SortMapKeys:            true,
+	ValidateJsonRawMessage: true,
+	CaseSensitive:          true,
+}.Froze()
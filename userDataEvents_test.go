@@ -0,0 +1,148 @@
+package appolloxapi
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestDecodeOrderTradeUpdate(t *testing.T) {
+	raw := []byte(`{"e":"ORDER_TRADE_UPDATE","E":1568879465651,"T":1568879465650,"o":{
+		"s":"BTCUSDT","c":"cid","S":"SELL","o":"LIMIT","f":"GTC","q":"0.001","p":"9000","ap":"0",
+		"x":"TRADE","X":"PARTIALLY_FILLED","i":8886774,"l":"0.0005","z":"0.0005","L":"9000",
+		"N":"USDT","n":"0.01","T":1568879465651,"t":7,"m":true,"R":false,"ps":"BOTH","rp":"1.5"}}`)
+	event, err := DecodeUserDataEvent(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	update, ok := event.(*OrderTradeUpdateEvent)
+	if !ok {
+		t.Fatalf("decoded as %T", event)
+	}
+	if update.EventType() != EventOrderTradeUpdate || update.Time != 1568879465651 || update.TransactionTime != 1568879465650 {
+		t.Fatalf("header %+v, transaction time %d", update.EventHeader, update.TransactionTime)
+	}
+	order := update.Order
+	// s and S, t and T are different fields
+	if order.Symbol != "BTCUSDT" || order.Side != "SELL" {
+		t.Fatalf("symbol %q side %q", order.Symbol, order.Side)
+	}
+	if order.TradeID != 7 || order.TradeTime != 1568879465651 {
+		t.Fatalf("trade id %d time %d", order.TradeID, order.TradeTime)
+	}
+	if order.Type != "LIMIT" || order.Status != "PARTIALLY_FILLED" || order.ExecType != "TRADE" || order.OrderID != 8886774 {
+		t.Fatalf("order %+v", order)
+	}
+	if !order.LastFilledPrice.Equal(decimal.NewFromInt(9000)) || !order.RealizedProfit.Equal(decimal.RequireFromString("1.5")) || !order.IsMaker {
+		t.Fatalf("fill %+v", order)
+	}
+}
+
+func TestDecodeUserDataEvents(t *testing.T) {
+	cases := []struct {
+		name  string
+		raw   string
+		check func(t *testing.T, event UserDataEvent)
+	}{
+		{
+			name: "account update",
+			raw: `{"e":"ACCOUNT_UPDATE","E":1,"T":2,"a":{"m":"ORDER",
+				"B":[{"a":"USDT","wb":"122.6","cw":"100.1","bc":"50"}],
+				"P":[{"s":"BTCUSDT","pa":"0.2","ep":"9000","cr":"200","up":"0.1","mt":"isolated","iw":"20","ps":"LONG"}]}}`,
+			check: func(t *testing.T, event UserDataEvent) {
+				update := event.(*AccountUpdateEvent).Update
+				if update.Reason != ReasonOrder || len(update.Balances) != 1 || len(update.Positions) != 1 {
+					t.Fatalf("update %+v", update)
+				}
+				if !update.Balances[0].BalanceChange.Equal(decimal.NewFromInt(50)) {
+					t.Fatalf("balance change %s", update.Balances[0].BalanceChange)
+				}
+				if position := update.Positions[0]; position.Symbol != "BTCUSDT" || position.PositionSide != "LONG" {
+					t.Fatalf("position %+v", position)
+				}
+			},
+		},
+		{
+			name: "margin call",
+			raw: `{"e":"MARGIN_CALL","E":1,"cw":"3.16","p":[{"s":"ETHUSDT","ps":"LONG","pa":"1.3","mt":"CROSSED",
+				"iw":"0","mp":"187.17","up":"-1.16","mm":"15.12"}]}`,
+			check: func(t *testing.T, event UserDataEvent) {
+				call := event.(*MarginCallEvent)
+				if len(call.Positions) != 1 || call.Positions[0].Symbol != "ETHUSDT" {
+					t.Fatalf("positions %+v", call.Positions)
+				}
+				if !call.Positions[0].MaintenanceMargin.Equal(decimal.RequireFromString("15.12")) {
+					t.Fatalf("maintenance margin %s", call.Positions[0].MaintenanceMargin)
+				}
+			},
+		},
+		{
+			name: "leverage update",
+			raw:  `{"e":"ACCOUNT_CONFIG_UPDATE","E":1,"T":2,"ac":{"s":"BTCUSDT","l":25}}`,
+			check: func(t *testing.T, event UserDataEvent) {
+				config := event.(*AccountConfigUpdateEvent)
+				if config.Leverage == nil || config.Leverage.Leverage != 25 || config.MultiAssets != nil {
+					t.Fatalf("config %+v", config)
+				}
+			},
+		},
+		{
+			name: "multi-assets update",
+			raw:  `{"e":"ACCOUNT_CONFIG_UPDATE","E":1,"T":2,"ai":{"j":true}}`,
+			check: func(t *testing.T, event UserDataEvent) {
+				config := event.(*AccountConfigUpdateEvent)
+				if config.MultiAssets == nil || !config.MultiAssets.MultiAssetsMargin || config.Leverage != nil {
+					t.Fatalf("config %+v", config)
+				}
+			},
+		},
+		{
+			name: "listen key expired",
+			raw:  `{"e":"listenKeyExpired","E":1576653824250}`,
+			check: func(t *testing.T, event UserDataEvent) {
+				if _, ok := event.(*ListenKeyExpiredEvent); !ok {
+					t.Fatalf("decoded as %T", event)
+				}
+				if event.EventTime().UnixNano()/int64(1e6) != 1576653824250 {
+					t.Fatalf("event time %s", event.EventTime())
+				}
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			event, err := DecodeUserDataEvent([]byte(tc.raw))
+			if err != nil {
+				t.Fatal(err)
+			}
+			tc.check(t, event)
+		})
+	}
+}
+
+func TestDecodeUserDataEventErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		raw  string
+		want error
+	}{
+		{"unknown type", `{"e":"STRATEGY_UPDATE","E":1}`, ErrUnknownEvent},
+		{"not json", `{"e":`, ErrInvalidEvent},
+		{"missing type", `{"E":1}`, ErrInvalidEvent},
+		{"bad field", `{"e":"ACCOUNT_UPDATE","E":1,"a":{"B":"balances"}}`, ErrInvalidEvent},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			event, err := DecodeUserDataEvent([]byte(tc.raw))
+			if event != nil || !errors.Is(err, tc.want) {
+				t.Fatalf("got %v, %v, want %s", event, err, tc.want)
+			}
+		})
+	}
+	_, err := DecodeUserDataEvent([]byte(`{"e":"STRATEGY_UPDATE","E":1}`))
+	var unknown *UnknownEventError
+	if !errors.As(err, &unknown) || unknown.Type != "STRATEGY_UPDATE" || len(unknown.Raw) == 0 {
+		t.Fatalf("want the unknown type kept, got %v", err)
+	}
+}
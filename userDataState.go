@@ -0,0 +1,187 @@
+package appolloxapi
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// connection state of LocalUserData
+type ConnState int
+
+const (
+	// getting a listen key and dialing, or the first snapshot is not in yet
+	ConnConnecting ConnState = iota
+	// connected and in sync with the snapshot
+	ConnLive
+	// connected again, the snapshot is being retaken
+	ConnResyncing
+	// after Close
+	ConnClosed
+)
+
+var ErrUserDataClosed = errors.New("local user data closed")
+
+// time Close gets to delete the listen key
+const deleteListenKeyTimeout = 5 * time.Second
+
+func (s ConnState) String() string {
+	switch s {
+	case ConnConnecting:
+		return "connecting"
+	case ConnLive:
+		return "live"
+	case ConnResyncing:
+		return "resyncing"
+	case ConnClosed:
+		return "closed"
+	}
+	return "unknown"
+}
+
+type streamState struct {
+	sync.RWMutex
+	// orders the handler calls
+	notify    sync.Mutex
+	state     ConnState
+	listenKey string
+	connected bool
+	synced    bool
+	ready     chan struct{}
+	readyOnce sync.Once
+	done      chan struct{}
+	closeOnce sync.Once
+	handlers  []func(ConnState)
+	// ask the stream for a fresh listen key, or maintainUserData for a new snapshot
+	reconnect chan struct{}
+	resync    chan struct{}
+}
+
+// closed once the first snapshot is in and the stream is connected
+func (u *UserDataBranch) Ready() <-chan struct{} {
+	return u.stream.ready
+}
+
+// returns ErrUserDataClosed when Close comes first
+func (u *UserDataBranch) WaitReady(ctx context.Context) error {
+	select {
+	case <-u.stream.ready:
+		return nil
+	case <-u.stream.done:
+		return ErrUserDataClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (u *UserDataBranch) ConnState() ConnState {
+	u.stream.RLock()
+	defer u.stream.RUnlock()
+	return u.stream.state
+}
+
+// every change of ConnState, one at a time
+func (u *UserDataBranch) OnConnState(handler func(ConnState)) {
+	u.stream.Lock()
+	defer u.stream.Unlock()
+	u.stream.handlers = append(u.stream.handlers, handler)
+}
+
+// internal funcs ------------------------------------------------
+
+func (u *UserDataBranch) initialStream() {
+	u.stream.ready = make(chan struct{})
+	u.stream.done = make(chan struct{})
+	u.stream.reconnect = make(chan struct{}, 1)
+	u.stream.resync = make(chan struct{}, 1)
+}
+
+// closed is final
+func (u *UserDataBranch) setConnState(state ConnState) {
+	u.stream.notify.Lock()
+	defer u.stream.notify.Unlock()
+	u.stream.Lock()
+	if u.stream.state == state || u.stream.state == ConnClosed {
+		u.stream.Unlock()
+		return
+	}
+	u.stream.state = state
+	handlers := u.stream.handlers
+	u.stream.Unlock()
+	for _, handler := range handlers {
+		handler(state)
+	}
+}
+
+func (u *UserDataBranch) setListenKey(listenKey string) {
+	u.stream.Lock()
+	defer u.stream.Unlock()
+	u.stream.listenKey = listenKey
+}
+
+// the websocket is up, a reconnect retakes the snapshot since events were missed
+func (u *UserDataBranch) streamConnected() {
+	u.stream.Lock()
+	first := !u.stream.connected
+	u.stream.connected = true
+	synced := u.stream.synced
+	u.stream.Unlock()
+	switch {
+	case first && synced:
+		u.markLive()
+	case !first:
+		u.setConnState(ConnResyncing)
+		select {
+		case u.stream.resync <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (u *UserDataBranch) streamDisconnected() {
+	u.setConnState(ConnConnecting)
+}
+
+// the snapshot is in, live once the websocket is up too
+func (u *UserDataBranch) snapshotTaken() {
+	u.stream.Lock()
+	u.stream.synced = true
+	connected := u.stream.connected
+	u.stream.Unlock()
+	if connected {
+		u.markLive()
+	}
+}
+
+func (u *UserDataBranch) markLive() {
+	u.setConnState(ConnLive)
+	u.stream.readyOnce.Do(func() {
+		close(u.stream.ready)
+	})
+}
+
+// listenKeyExpired, the stream drops the key and gets a new one
+func (u *UserDataBranch) requestReconnect() {
+	select {
+	case u.stream.reconnect <- struct{}{}:
+	default:
+	}
+}
+
+// stop everything and give the listen key back, it expires by itself when the delete fails
+func (u *UserDataBranch) closeStream() {
+	u.stream.closeOnce.Do(func() {
+		(*u.cancel)()
+		close(u.stream.done)
+		u.stream.RLock()
+		listenKey := u.stream.listenKey
+		u.stream.RUnlock()
+		if listenKey != "" && u.client != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), deleteListenKeyTimeout)
+			u.client.DeleteListenKeyWithContext(ctx, listenKey)
+			cancel()
+		}
+		u.setConnState(ConnClosed)
+	})
+}
@@ -0,0 +1,201 @@
+package appolloxapi
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// execution types of ORDER_TRADE_UPDATE
+const (
+	ExecTypeNew        = "NEW"
+	ExecTypeCanceled   = "CANCELED"
+	ExecTypeCalculated = "CALCULATED"
+	ExecTypeExpired    = "EXPIRED"
+	ExecTypeTrade      = "TRADE"
+	ExecTypeAmendment  = "AMENDMENT"
+)
+
+const (
+	OrderStatusNew             = "NEW"
+	OrderStatusPartiallyFilled = "PARTIALLY_FILLED"
+	OrderStatusFilled          = "FILLED"
+	OrderStatusCanceled        = "CANCELED"
+	OrderStatusExpired         = "EXPIRED"
+	OrderStatusNewInsurance    = "NEW_INSURANCE"
+	OrderStatusNewADL          = "NEW_ADL"
+)
+
+// finished orders are still returned by Order for a while, up to one snapshot interval longer
+const closedOrderRetention = 10 * time.Minute
+
+// live view of one order, built from the snapshot and the ORDER_TRADE_UPDATE events
+type OrderState struct {
+	Symbol          string
+	OrderID         int64
+	ClientOrderID   string
+	Side            string
+	PositionSide    string
+	Type            string
+	OrigType        string
+	TimeInForce     string
+	Status          string
+	ExecType        string
+	Price           decimal.Decimal
+	StopPrice       decimal.Decimal
+	OrigQty         decimal.Decimal
+	FilledQty       decimal.Decimal
+	AvgPrice        decimal.Decimal
+	LastFilledQty   decimal.Decimal
+	LastFilledPrice decimal.Decimal
+	ReduceOnly      bool
+	ClosePosition   bool
+	// first seen, the order time when it came from the snapshot
+	CreateTime time.Time
+	UpdateTime time.Time
+}
+
+type orderBranch struct {
+	sync.RWMutex
+	byID map[int64]*OrderState
+}
+
+func (o *OrderState) IsOpen() bool {
+	return o.Status == OrderStatusNew || o.Status == OrderStatusPartiallyFilled
+}
+
+// open orders of the symbol sorted by order id, empty symbol for all
+func (u *UserDataBranch) OpenOrders(symbol string) []OrderState {
+	u.orders.RLock()
+	defer u.orders.RUnlock()
+	list := []OrderState{}
+	for _, order := range u.orders.byID {
+		if !order.IsOpen() || (symbol != "" && order.Symbol != symbol) {
+			continue
+		}
+		list = append(list, *order)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].OrderID < list[j].OrderID
+	})
+	return list
+}
+
+// open or recently finished order
+func (u *UserDataBranch) Order(orderID int64) (OrderState, bool) {
+	u.orders.RLock()
+	defer u.orders.RUnlock()
+	order, ok := u.orders.byID[orderID]
+	if !ok {
+		return OrderState{}, false
+	}
+	return *order, true
+}
+
+// internal funcs ------------------------------------------------
+
+// replace the open orders with the rest snapshot, the stream keeps it up from here
+func (u *UserDataBranch) getOpenOrdersSnapShot(ctx context.Context, client *Client) error {
+	res, err := client.GetCurrentOrdersWithContext(ctx, "")
+	if err != nil {
+		return err
+	}
+	u.orders.Lock()
+	defer u.orders.Unlock()
+	byID := make(map[int64]*OrderState, len(res))
+	for _, item := range res {
+		order := &OrderState{
+			Symbol:        item.Symbol,
+			OrderID:       item.Orderid,
+			ClientOrderID: item.Clientorderid,
+			Side:          item.Side,
+			PositionSide:  item.Positionside,
+			Type:          item.Type,
+			OrigType:      item.Origtype,
+			TimeInForce:   item.Timeinforce,
+			Status:        item.Status,
+			ReduceOnly:    item.Reduceonly,
+			ClosePosition: item.Closeposition,
+			CreateTime:    time.Unix(0, item.Time*int64(time.Millisecond)),
+			UpdateTime:    time.Unix(0, item.Updatetime*int64(time.Millisecond)),
+		}
+		parseDecimals(
+			item.Price, &order.Price,
+			item.Stopprice, &order.StopPrice,
+			item.Origqty, &order.OrigQty,
+			item.Executedqty, &order.FilledQty,
+			item.Avgprice, &order.AvgPrice,
+		)
+		// an update newer than the snapshot wins
+		if known, ok := u.orders.byID[order.OrderID]; ok && known.UpdateTime.After(order.UpdateTime) {
+			order = known
+		}
+		byID[order.OrderID] = order
+	}
+	// finished ones are kept for Order
+	for id, known := range u.orders.byID {
+		if _, ok := byID[id]; !ok && !known.IsOpen() {
+			byID[id] = known
+		}
+	}
+	u.orders.byID = byID
+	return nil
+}
+
+func (u *UserDataBranch) handleOrderUpdate(update *OrderUpdate) {
+	stamp := time.Now()
+	if update.TradeTime != 0 {
+		stamp = time.Unix(0, update.TradeTime*int64(time.Millisecond))
+	}
+	u.orders.Lock()
+	order, ok := u.orders.byID[update.OrderID]
+	if !ok {
+		order = &OrderState{
+			OrderID:    update.OrderID,
+			CreateTime: stamp,
+		}
+		u.orders.byID[order.OrderID] = order
+	}
+	if order.UpdateTime.After(stamp) {
+		// stale, the snapshot is newer
+		u.orders.Unlock()
+		return
+	}
+	order.Symbol = update.Symbol
+	order.ClientOrderID = update.ClientOrderID
+	order.Side = update.Side
+	order.PositionSide = update.PositionSide
+	order.Type = update.Type
+	order.OrigType = update.OrigType
+	order.TimeInForce = update.TimeInForce
+	order.Status = update.Status
+	order.ExecType = update.ExecType
+	order.Price = update.Price
+	order.StopPrice = update.StopPrice
+	order.OrigQty = update.OrigQty
+	order.FilledQty = update.FilledQty
+	order.AvgPrice = update.AvgPrice
+	order.LastFilledQty = update.LastFilledQty
+	order.LastFilledPrice = update.LastFilledPrice
+	order.ReduceOnly = update.ReduceOnly
+	order.ClosePosition = update.ClosePosition
+	order.UpdateTime = stamp
+	state := *order
+	u.orders.Unlock()
+	// see SubscribeOrders and OnOrder
+	u.subs.publish(TopicOrders, state)
+}
+
+// on the snapshot ticker, so a busy stream does not scan every order per event
+func (u *UserDataBranch) pruneClosedOrders(now time.Time) {
+	u.orders.Lock()
+	defer u.orders.Unlock()
+	for id, order := range u.orders.byID {
+		if !order.IsOpen() && now.Sub(order.UpdateTime) > closedOrderRetention {
+			delete(u.orders.byID, id)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package appolloxapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPruneClosedOrders(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-2 * closedOrderRetention)
+	var u UserDataBranch
+	u.orders.byID = map[int64]*OrderState{
+		1: {OrderID: 1, Status: OrderStatusFilled, UpdateTime: old},
+		2: {OrderID: 2, Status: OrderStatusCanceled, UpdateTime: now},
+		3: {OrderID: 3, Status: OrderStatusNew, UpdateTime: old},
+	}
+	u.pruneClosedOrders(now)
+	if _, ok := u.Order(1); ok {
+		t.Fatal("old finished order kept")
+	}
+	if _, ok := u.Order(2); !ok {
+		t.Fatal("recent finished order pruned")
+	}
+	if _, ok := u.Order(3); !ok {
+		t.Fatal("open order pruned")
+	}
+}